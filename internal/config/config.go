@@ -18,6 +18,39 @@ type Config struct {
 	JWTSecret       string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+
+	// OrderArchiveAfter is the age after which finished or deleted orders are
+	// moved to the orders_archive collection.
+	OrderArchiveAfter    time.Duration
+	OrderArchiveInterval time.Duration
+
+	// DailyOrderNumbers restarts the order number sequence every day and
+	// prefixes numbers with the date.
+	DailyOrderNumbers bool
+
+	// StockReservationTTL is how long POST /cart/reserve holds stock.
+	StockReservationTTL time.Duration
+
+	// IdempotencyTTL is how long stored Idempotency-Key responses are kept.
+	IdempotencyTTL time.Duration
+
+	// GuestCartTTL is how long an untouched guest cart and its token live.
+	GuestCartTTL time.Duration
+
+	// DeliveryLocation is the time zone delivery slots are scheduled in.
+	DeliveryLocation *time.Location
+
+	// PublicBaseURL is the externally reachable base URL of this API, used
+	// for payment callback and webhook URLs.
+	PublicBaseURL string
+
+	// PaymentProvider names the card payment provider; card payments are
+	// disabled when it is empty. PaymentWebhookSecret verifies its webhooks
+	// and is required with a provider. The fake provider approves every
+	// payment, so it is only accepted when PaymentAllowFake is set.
+	PaymentProvider      string
+	PaymentWebhookSecret string
+	PaymentAllowFake     bool
 }
 
 func Load() {
@@ -30,6 +63,25 @@ func Load() {
 		JWTSecret:       getEnvOrDefault("JWT_SECRET", ""),
 		AccessTokenTTL:  getDurationEnv("ACCESS_TOKEN_TTL", 20, time.Minute),
 		RefreshTokenTTL: getDurationEnv("REFRESH_TOKEN_TTL", 7, 24*time.Hour),
+
+		OrderArchiveAfter:    getDurationEnv("ORDER_ARCHIVE_AFTER_DAYS", 365, 24*time.Hour),
+		OrderArchiveInterval: getDurationEnv("ORDER_ARCHIVE_INTERVAL_HOURS", 24, time.Hour),
+
+		DailyOrderNumbers: getBoolEnv("ORDER_NUMBER_DAILY", false),
+
+		StockReservationTTL: getDurationEnv("STOCK_RESERVATION_TTL_MINUTES", 15, time.Minute),
+
+		IdempotencyTTL: getDurationEnv("IDEMPOTENCY_TTL_HOURS", 24, time.Hour),
+
+		GuestCartTTL: getDurationEnv("GUEST_CART_TTL_DAYS", 30, 24*time.Hour),
+
+		DeliveryLocation: getLocationEnv("DELIVERY_TIMEZONE", "Europe/Istanbul"),
+
+		PublicBaseURL: strings.TrimRight(getEnvOrDefault("PUBLIC_BASE_URL", "http://localhost:8080"), "/"),
+
+		PaymentProvider:      getEnvOrDefault("PAYMENT_PROVIDER", ""),
+		PaymentWebhookSecret: getEnvOrDefault("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentAllowFake:     getBoolEnv("PAYMENT_ALLOW_FAKE", false),
 	}
 }
 
@@ -48,3 +100,22 @@ func getDurationEnv(key string, defaultValue int, unit time.Duration) time.Durat
 	}
 	return time.Duration(defaultValue) * unit
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getLocationEnv(key, defaultValue string) *time.Location {
+	name := getEnvOrDefault(key, defaultValue)
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("%s %q not loaded, using local time: %v", key, name, err)
+		return time.Local
+	}
+	return loc
+}
@@ -16,6 +16,8 @@ func EnsureProductIndexes(db *mongo.Database) error {
 
 	indexes := db.Collection("products").Indexes()
 
+	// Variants are product documents too, so one index keeps barcodes
+	// unique across products and all their variants.
 	barcodeIndex := mongo.IndexModel{
 		Keys: bson.D{{Key: "barcode", Value: 1}},
 		Options: options.Index().
@@ -35,6 +37,19 @@ func EnsureProductIndexes(db *mongo.Database) error {
 		return err
 	}
 	log.Println("EnsureProductIndexes: barcode_unique index created")
+
+	// Grouped listings load the variants of a page of products.
+	parentIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "parentId", Value: 1}},
+		Options: options.Index().SetName("parentId_index"),
+	}
+
+	log.Println("EnsureProductIndexes: creating parentId_index")
+	if _, err := indexes.CreateOne(ctx, parentIndex); err != nil {
+		log.Println("EnsureProductIndexes: parent index error:", err)
+		return err
+	}
+	log.Println("EnsureProductIndexes: parentId_index created")
 	return nil
 }
 
@@ -79,5 +94,354 @@ func EnsureOrderIndexes(db *mongo.Database) error {
 		return err
 	}
 	log.Println("EnsureOrderIndexes: userId_index index created")
+
+	createdAtIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("createdAt_index"),
+	}
+
+	log.Println("EnsureOrderIndexes: creating createdAt_index index")
+	_, err = indexes.CreateOne(ctx, createdAtIndex)
+	if err != nil {
+		log.Println("EnsureOrderIndexes: createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsureOrderIndexes: createdAt_index index created")
+
+	numberIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "number", Value: 1}},
+		Options: options.Index().
+			SetName("number_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{
+				"number": bson.M{
+					"$exists": true,
+				},
+			}),
+	}
+
+	log.Println("EnsureOrderIndexes: creating number_unique index")
+	_, err = indexes.CreateOne(ctx, numberIndex)
+	if err != nil {
+		log.Println("EnsureOrderIndexes: number index error:", err)
+		return err
+	}
+	log.Println("EnsureOrderIndexes: number_unique index created")
+	return nil
+}
+
+func EnsureIdempotencyIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("idempotency_keys").Indexes()
+
+	expiresAtIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().
+			SetName("expiresAt_ttl").
+			SetExpireAfterSeconds(0),
+	}
+
+	log.Println("EnsureIdempotencyIndexes: creating expiresAt_ttl index")
+	_, err := indexes.CreateOne(ctx, expiresAtIndex)
+	if err != nil {
+		log.Println("EnsureIdempotencyIndexes: expiresAt index error:", err)
+		return err
+	}
+	log.Println("EnsureIdempotencyIndexes: expiresAt_ttl index created")
+	return nil
+}
+
+func EnsureReservationIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("stock_reservations").Indexes()
+
+	statusExpiresIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetName("status_expiresAt_index"),
+	}
+
+	log.Println("EnsureReservationIndexes: creating status_expiresAt_index index")
+	_, err := indexes.CreateOne(ctx, statusExpiresIndex)
+	if err != nil {
+		log.Println("EnsureReservationIndexes: status_expiresAt index error:", err)
+		return err
+	}
+	log.Println("EnsureReservationIndexes: status_expiresAt_index index created")
+
+	userIDIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index().SetName("userId_status_index"),
+	}
+
+	log.Println("EnsureReservationIndexes: creating userId_status_index index")
+	_, err = indexes.CreateOne(ctx, userIDIndex)
+	if err != nil {
+		log.Println("EnsureReservationIndexes: userId_status index error:", err)
+		return err
+	}
+	log.Println("EnsureReservationIndexes: userId_status_index index created")
+	return nil
+}
+
+func EnsureStockMovementIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("stock_movements").Indexes()
+
+	productIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "productId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("productId_createdAt_index"),
+	}
+
+	log.Println("EnsureStockMovementIndexes: creating productId_createdAt_index index")
+	_, err := indexes.CreateOne(ctx, productIndex)
+	if err != nil {
+		log.Println("EnsureStockMovementIndexes: productId_createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsureStockMovementIndexes: productId_createdAt_index index created")
+	return nil
+}
+
+func EnsureCartIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("carts").Indexes()
+
+	userIDIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().
+			SetName("userId_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{
+				"userId": bson.M{
+					"$exists": true,
+				},
+			}),
+	}
+
+	log.Println("EnsureCartIndexes: creating userId_unique index")
+	_, err := indexes.CreateOne(ctx, userIDIndex)
+	if err != nil {
+		log.Println("EnsureCartIndexes: userId index error:", err)
+		return err
+	}
+	log.Println("EnsureCartIndexes: userId_unique index created")
+
+	guestIDIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "guestId", Value: 1}},
+		Options: options.Index().
+			SetName("guestId_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{
+				"guestId": bson.M{
+					"$exists": true,
+				},
+			}),
+	}
+
+	log.Println("EnsureCartIndexes: creating guestId_unique index")
+	_, err = indexes.CreateOne(ctx, guestIDIndex)
+	if err != nil {
+		log.Println("EnsureCartIndexes: guestId index error:", err)
+		return err
+	}
+	log.Println("EnsureCartIndexes: guestId_unique index created")
+
+	// Only guest carts carry expiresAt, so user carts are never expired.
+	expiresAtIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().
+			SetName("expiresAt_ttl").
+			SetExpireAfterSeconds(0),
+	}
+
+	log.Println("EnsureCartIndexes: creating expiresAt_ttl index")
+	_, err = indexes.CreateOne(ctx, expiresAtIndex)
+	if err != nil {
+		log.Println("EnsureCartIndexes: expiresAt index error:", err)
+		return err
+	}
+	log.Println("EnsureCartIndexes: expiresAt_ttl index created")
+	return nil
+}
+
+func EnsureCouponIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	codeIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetName("code_unique").SetUnique(true),
+	}
+
+	log.Println("EnsureCouponIndexes: creating code_unique index")
+	if _, err := db.Collection("coupons").Indexes().CreateOne(ctx, codeIndex); err != nil {
+		log.Println("EnsureCouponIndexes: code index error:", err)
+		return err
+	}
+	log.Println("EnsureCouponIndexes: code_unique index created")
+
+	// Per-user limits count redemptions by coupon and user.
+	redemptionIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "couponId", Value: 1},
+			{Key: "userId", Value: 1},
+		},
+		Options: options.Index().SetName("couponId_userId_index"),
+	}
+
+	log.Println("EnsureCouponIndexes: creating couponId_userId_index")
+	if _, err := db.Collection("coupon_redemptions").Indexes().CreateOne(ctx, redemptionIndex); err != nil {
+		log.Println("EnsureCouponIndexes: redemption index error:", err)
+		return err
+	}
+	log.Println("EnsureCouponIndexes: couponId_userId_index created")
+	return nil
+}
+
+func EnsureCampaignIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Every priced read looks up the campaigns running now.
+	activeIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "isActive", Value: 1},
+			{Key: "startsAt", Value: 1},
+			{Key: "endsAt", Value: 1},
+		},
+		Options: options.Index().SetName("isActive_startsAt_endsAt_index"),
+	}
+
+	log.Println("EnsureCampaignIndexes: creating isActive_startsAt_endsAt_index")
+	if _, err := db.Collection("campaigns").Indexes().CreateOne(ctx, activeIndex); err != nil {
+		log.Println("EnsureCampaignIndexes: active index error:", err)
+		return err
+	}
+	log.Println("EnsureCampaignIndexes: isActive_startsAt_endsAt_index created")
+	return nil
+}
+
+func EnsurePaymentIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Webhooks find the payment by the provider's id. The id is set after
+	// the payment is stored, hence the partial filter.
+	providerIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "provider", Value: 1},
+			{Key: "providerPaymentId", Value: 1},
+		},
+		Options: options.Index().
+			SetName("provider_providerPaymentId_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"providerPaymentId": bson.M{"$type": "string"}}),
+	}
+
+	log.Println("EnsurePaymentIndexes: creating provider_providerPaymentId_unique")
+	if _, err := db.Collection("payments").Indexes().CreateOne(ctx, providerIndex); err != nil {
+		log.Println("EnsurePaymentIndexes: provider index error:", err)
+		return err
+	}
+	log.Println("EnsurePaymentIndexes: provider_providerPaymentId_unique created")
+
+	orderIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "orderId", Value: 1}},
+		Options: options.Index().SetName("orderId_index"),
+	}
+
+	log.Println("EnsurePaymentIndexes: creating orderId_index")
+	if _, err := db.Collection("payments").Indexes().CreateOne(ctx, orderIndex); err != nil {
+		log.Println("EnsurePaymentIndexes: order index error:", err)
+		return err
+	}
+	log.Println("EnsurePaymentIndexes: orderId_index created")
+
+	// The capture retry job looks for payments whose retry is due; only
+	// payments with a failed capture carry the field.
+	captureIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "nextCaptureAt", Value: 1}},
+		Options: options.Index().
+			SetName("nextCaptureAt_partial").
+			SetPartialFilterExpression(bson.M{"nextCaptureAt": bson.M{"$exists": true}}),
+	}
+
+	log.Println("EnsurePaymentIndexes: creating nextCaptureAt_partial")
+	if _, err := db.Collection("payments").Indexes().CreateOne(ctx, captureIndex); err != nil {
+		log.Println("EnsurePaymentIndexes: capture index error:", err)
+		return err
+	}
+	log.Println("EnsurePaymentIndexes: nextCaptureAt_partial created")
+	return nil
+}
+
+func EnsureDeliveryZoneIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Orders look up the zone containing the delivery address.
+	areaIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "area", Value: "2dsphere"}},
+		Options: options.Index().SetName("area_2dsphere"),
+	}
+
+	log.Println("EnsureDeliveryZoneIndexes: creating area_2dsphere")
+	if _, err := db.Collection("delivery_zones").Indexes().CreateOne(ctx, areaIndex); err != nil {
+		log.Println("EnsureDeliveryZoneIndexes: area index error:", err)
+		return err
+	}
+	log.Println("EnsureDeliveryZoneIndexes: area_2dsphere created")
+	return nil
+}
+
+func EnsurePromotionIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	activeIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "isActive", Value: 1},
+			{Key: "startsAt", Value: 1},
+			{Key: "endsAt", Value: 1},
+		},
+		Options: options.Index().SetName("isActive_startsAt_endsAt_index"),
+	}
+
+	log.Println("EnsurePromotionIndexes: creating isActive_startsAt_endsAt_index")
+	if _, err := db.Collection("promotions").Indexes().CreateOne(ctx, activeIndex); err != nil {
+		log.Println("EnsurePromotionIndexes: active index error:", err)
+		return err
+	}
+	log.Println("EnsurePromotionIndexes: isActive_startsAt_endsAt_index created")
+	return nil
+}
+
+func EnsureNotificationIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Users list their notifications newest first.
+	userIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "userId", Value: 1},
+			{Key: "createdAt", Value: -1},
+		},
+		Options: options.Index().SetName("userId_createdAt_index"),
+	}
+
+	log.Println("EnsureNotificationIndexes: creating userId_createdAt_index")
+	if _, err := db.Collection("notifications").Indexes().CreateOne(ctx, userIndex); err != nil {
+		log.Println("EnsureNotificationIndexes: user index error:", err)
+		return err
+	}
+	log.Println("EnsureNotificationIndexes: userId_createdAt_index created")
 	return nil
 }
@@ -0,0 +1,67 @@
+// Package delivery holds delivery pricing shared by order creation, quotes
+// and the admin settings endpoint.
+package delivery
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+const settingsID = "delivery"
+
+// LoadSettings returns the stored delivery settings. Until an admin saves
+// them delivery is free with no minimum.
+func LoadSettings(ctx context.Context, db *mongo.Database) (models.DeliverySettings, error) {
+	var settings models.DeliverySettings
+	err := db.Collection("settings").FindOne(ctx, bson.M{"_id": settingsID}).Decode(&settings)
+	if err == mongo.ErrNoDocuments {
+		return models.DeliverySettings{ID: settingsID}, nil
+	}
+	return settings, err
+}
+
+// SaveSettings replaces the delivery pricing and returns the stored document.
+func SaveSettings(ctx context.Context, db *mongo.Database, pricing models.DeliveryPricing, updatedBy string) (models.DeliverySettings, error) {
+	settings := models.DeliverySettings{
+		ID:              settingsID,
+		DeliveryPricing: pricing,
+		UpdatedAt:       time.Now(),
+		UpdatedBy:       updatedBy,
+	}
+	_, err := db.Collection("settings").ReplaceOne(
+		ctx,
+		bson.M{"_id": settingsID},
+		settings,
+		options.Replace().SetUpsert(true),
+	)
+	return settings, err
+}
+
+// Resolve returns the pricing for an order: override, when given (e.g. from a
+// delivery zone), replaces the default.
+func Resolve(settings models.DeliverySettings, override *models.DeliveryPricing) models.DeliveryPricing {
+	if override != nil {
+		return *override
+	}
+	return settings.DeliveryPricing
+}
+
+// Fee returns the delivery fee for a basket worth goodsTotal.
+func Fee(pricing models.DeliveryPricing, goodsTotal float64) float64 {
+	if pricing.FreeDeliveryThreshold > 0 && goodsTotal >= pricing.FreeDeliveryThreshold {
+		return 0
+	}
+	return math.Round(pricing.Fee*100) / 100
+}
+
+// MeetsMinimum reports whether goodsTotal reaches the minimum order amount.
+func MeetsMinimum(pricing models.DeliveryPricing, goodsTotal float64) bool {
+	return pricing.MinOrderAmount <= 0 || goodsTotal >= pricing.MinOrderAmount
+}
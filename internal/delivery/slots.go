@@ -0,0 +1,305 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+const (
+	slotScheduleID = "delivery_slots"
+	slotDateLayout = "2006-01-02"
+
+	// DefaultDaysAhead is used when the schedule does not say how far ahead
+	// slots can be booked.
+	DefaultDaysAhead = 7
+	// MaxDaysAhead bounds the schedule and GET /delivery/slots.
+	MaxDaysAhead = 30
+)
+
+var (
+	// ErrSlotNotFound means the slot id does not match the schedule.
+	ErrSlotNotFound = errors.New("delivery slot not found")
+	// ErrSlotClosed means the slot's cut-off has passed or its day is a
+	// holiday or out of the booking range.
+	ErrSlotClosed = errors.New("delivery slot closed")
+	// ErrSlotFull means the slot has no capacity left.
+	ErrSlotFull = errors.New("delivery slot full")
+)
+
+// Slot is one bookable occurrence of a schedule window.
+type Slot struct {
+	ID       string    `json:"id"`
+	Start    string    `json:"start"`
+	End      string    `json:"end"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	CutoffAt time.Time `json:"cutoffAt"`
+	Capacity int       `json:"capacity"`
+}
+
+// LoadSlotSchedule returns the stored slot schedule. Until an admin saves one
+// there are no slots and none are required.
+func LoadSlotSchedule(ctx context.Context, db *mongo.Database) (models.DeliverySlotSchedule, error) {
+	var schedule models.DeliverySlotSchedule
+	err := db.Collection("settings").FindOne(ctx, bson.M{"_id": slotScheduleID}).Decode(&schedule)
+	if err == mongo.ErrNoDocuments {
+		return models.DeliverySlotSchedule{ID: slotScheduleID}, nil
+	}
+	return schedule, err
+}
+
+// SaveSlotSchedule replaces the slot schedule and returns the stored
+// document. Bookings already made are kept.
+func SaveSlotSchedule(ctx context.Context, db *mongo.Database, schedule models.DeliverySlotSchedule, updatedBy string) (models.DeliverySlotSchedule, error) {
+	schedule.ID = slotScheduleID
+	schedule.UpdatedAt = time.Now()
+	schedule.UpdatedBy = updatedBy
+	_, err := db.Collection("settings").ReplaceOne(
+		ctx,
+		bson.M{"_id": slotScheduleID},
+		schedule,
+		options.Replace().SetUpsert(true),
+	)
+	return schedule, err
+}
+
+// ValidateSchedule checks windows, holidays and the booking range.
+func ValidateSchedule(schedule models.DeliverySlotSchedule) error {
+	if schedule.DaysAhead < 0 || schedule.DaysAhead > MaxDaysAhead {
+		return fmt.Errorf("daysAhead must be between 0 and %d", MaxDaysAhead)
+	}
+	seen := map[string]bool{}
+	for _, window := range schedule.Windows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return errors.New("window start must be HH:MM")
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return errors.New("window end must be HH:MM")
+		}
+		if end <= start {
+			return errors.New("window end must be after start")
+		}
+		if window.Capacity <= 0 {
+			return errors.New("window capacity must be greater than zero")
+		}
+		if window.CutoffMinutes < 0 {
+			return errors.New("window cutoffMinutes cannot be negative")
+		}
+		for _, weekday := range window.Weekdays {
+			if weekday < 0 || weekday > 6 {
+				return errors.New("weekdays must be between 0 (Sunday) and 6")
+			}
+		}
+		for _, weekday := range windowWeekdays(window) {
+			key := fmt.Sprintf("%d_%s-%s", weekday, window.Start, window.End)
+			if seen[key] {
+				return errors.New("duplicate window " + window.Start + "-" + window.End)
+			}
+			seen[key] = true
+		}
+	}
+	for _, holiday := range schedule.Holidays {
+		if _, err := time.Parse(slotDateLayout, holiday); err != nil {
+			return errors.New("holidays must be YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// DaysAhead returns how many days after today can be booked.
+func DaysAhead(schedule models.DeliverySlotSchedule) int {
+	if schedule.DaysAhead <= 0 {
+		return DefaultDaysAhead
+	}
+	return schedule.DaysAhead
+}
+
+// IsHoliday reports whether day (a "YYYY-MM-DD" date) has no deliveries.
+func IsHoliday(schedule models.DeliverySlotSchedule, day string) bool {
+	for _, holiday := range schedule.Holidays {
+		if holiday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// SlotsForDay returns the slots of the schedule on the date of day in loc,
+// ordered by start time. Holidays have none.
+func SlotsForDay(schedule models.DeliverySlotSchedule, day time.Time, loc *time.Location) []Slot {
+	day = day.In(loc)
+	date := day.Format(slotDateLayout)
+	if IsHoliday(schedule, date) {
+		return nil
+	}
+
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	slots := []Slot{}
+	for _, window := range schedule.Windows {
+		if !containsWeekday(windowWeekdays(window), int(day.Weekday())) {
+			continue
+		}
+		start, errStart := parseClock(window.Start)
+		end, errEnd := parseClock(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		startsAt := clockOn(midnight, start)
+		slots = append(slots, Slot{
+			ID:       SlotID(date, window),
+			Start:    window.Start,
+			End:      window.End,
+			StartsAt: startsAt,
+			EndsAt:   clockOn(midnight, end),
+			CutoffAt: startsAt.Add(-time.Duration(window.CutoffMinutes) * time.Minute),
+			Capacity: window.Capacity,
+		})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].StartsAt.Before(slots[j].StartsAt)
+	})
+	return slots
+}
+
+// SlotID builds the id of window on date ("YYYY-MM-DD").
+func SlotID(date string, window models.DeliverySlotWindow) string {
+	return date + "_" + window.Start + "-" + window.End
+}
+
+// FindSlot resolves a slot id against the schedule and checks it can still
+// be booked at now: within the booking range, not a holiday and before its
+// cut-off. Capacity is checked by BookSlot.
+func FindSlot(schedule models.DeliverySlotSchedule, id string, now time.Time, loc *time.Location) (Slot, error) {
+	date, _, ok := strings.Cut(strings.TrimSpace(id), "_")
+	if !ok {
+		return Slot{}, ErrSlotNotFound
+	}
+	day, err := time.ParseInLocation(slotDateLayout, date, loc)
+	if err != nil {
+		return Slot{}, ErrSlotNotFound
+	}
+
+	for _, slot := range SlotsForDay(schedule, day, loc) {
+		if slot.ID != id {
+			continue
+		}
+		today := now.In(loc)
+		today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+		if day.Before(today) || day.After(today.AddDate(0, 0, DaysAhead(schedule))) {
+			return Slot{}, ErrSlotClosed
+		}
+		if !now.Before(slot.CutoffAt) {
+			return Slot{}, ErrSlotClosed
+		}
+		return slot, nil
+	}
+
+	if IsHoliday(schedule, date) {
+		return Slot{}, ErrSlotClosed
+	}
+	return Slot{}, ErrSlotNotFound
+}
+
+// BookedCounts returns how many orders are booked into each of the slot ids.
+func BookedCounts(ctx context.Context, db *mongo.Database, ids []string) (map[string]int, error) {
+	counts := make(map[string]int, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	cursor, err := db.Collection("delivery_slot_bookings").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bookings []models.DeliverySlotBooking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		counts[booking.ID] = booking.Booked
+	}
+	return counts, nil
+}
+
+// BookSlot takes one place in slot, failing with ErrSlotFull when it has no
+// capacity left. Run it inside the order transaction: the capacity check and
+// the increment are a single update, and concurrent bookings of the same
+// slot conflict and retry.
+func BookSlot(ctx context.Context, db *mongo.Database, slot Slot) error {
+	// A full slot does not match the filter, so the upsert tries to insert
+	// its id again and fails with a duplicate key error.
+	_, err := db.Collection("delivery_slot_bookings").UpdateOne(
+		ctx,
+		bson.M{"_id": slot.ID, "booked": bson.M{"$lt": slot.Capacity}},
+		bson.M{
+			"$inc":         bson.M{"booked": 1},
+			"$set":         bson.M{"updatedAt": time.Now()},
+			"$setOnInsert": bson.M{"startsAt": slot.StartsAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlotFull
+	}
+	return err
+}
+
+// ReleaseSlot gives back the place an order held in slot id.
+func ReleaseSlot(ctx context.Context, db *mongo.Database, id string) error {
+	_, err := db.Collection("delivery_slot_bookings").UpdateOne(
+		ctx,
+		bson.M{"_id": id, "booked": bson.M{"$gt": 0}},
+		bson.M{
+			"$inc": bson.M{"booked": -1},
+			"$set": bson.M{"updatedAt": time.Now()},
+		},
+	)
+	return err
+}
+
+func windowWeekdays(window models.DeliverySlotWindow) []int {
+	if len(window.Weekdays) == 0 {
+		return []int{0, 1, 2, 3, 4, 5, 6}
+	}
+	return window.Weekdays
+}
+
+func containsWeekday(weekdays []int, weekday int) bool {
+	for _, d := range weekdays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// clockOn returns the time minutes after midnight. time.Date normalises the
+// wall clock, so DST days still land on the scheduled local time.
+func clockOn(midnight time.Time, minutes int) time.Time {
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(), minutes/60, minutes%60, 0, 0, midnight.Location())
+}
@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"backend/internal/models"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func eveningSchedule() models.DeliverySlotSchedule {
+	return models.DeliverySlotSchedule{
+		Windows: []models.DeliverySlotWindow{
+			{Start: "18:00", End: "20:00", Capacity: 5, CutoffMinutes: 90},
+			{Weekdays: []int{6}, Start: "10:00", End: "12:00", Capacity: 5},
+		},
+		Holidays: []string{"2026-10-29"},
+	}
+}
+
+func TestFindSlotCutoff(t *testing.T) {
+	loc := berlin(t)
+	schedule := eveningSchedule()
+	id := "2026-10-20_18:00-20:00"
+
+	before := time.Date(2026, 10, 20, 16, 29, 0, 0, loc)
+	slot, err := FindSlot(schedule, id, before, loc)
+	if err != nil {
+		t.Fatalf("one minute before the cut-off: %v", err)
+	}
+	if want := time.Date(2026, 10, 20, 16, 30, 0, 0, loc); !slot.CutoffAt.Equal(want) {
+		t.Errorf("cut-off = %v, want %v", slot.CutoffAt, want)
+	}
+
+	// Booking closes at the cut-off itself, not a minute later.
+	if _, err := FindSlot(schedule, id, slot.CutoffAt, loc); !errors.Is(err, ErrSlotClosed) {
+		t.Errorf("at the cut-off: err = %v, want ErrSlotClosed", err)
+	}
+}
+
+func TestFindSlotHolidayAndWeekdays(t *testing.T) {
+	loc := berlin(t)
+	schedule := eveningSchedule()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, loc)
+
+	if _, err := FindSlot(schedule, "2026-10-29_18:00-20:00", now, loc); !errors.Is(err, ErrSlotClosed) {
+		t.Errorf("holiday: err = %v, want ErrSlotClosed", err)
+	}
+	// 2026-10-24 is a Saturday, 2026-10-23 a Friday.
+	if _, err := FindSlot(schedule, "2026-10-24_10:00-12:00", now, loc); err != nil {
+		t.Errorf("Saturday morning window: %v", err)
+	}
+	if _, err := FindSlot(schedule, "2026-10-23_10:00-12:00", now, loc); !errors.Is(err, ErrSlotNotFound) {
+		t.Errorf("Saturday window on a Friday: err = %v, want ErrSlotNotFound", err)
+	}
+	if _, err := FindSlot(schedule, "2026-10-27_18:00-20:00", now, loc); !errors.Is(err, ErrSlotClosed) {
+		t.Errorf("past the default booking range: err = %v, want ErrSlotClosed", err)
+	}
+}
+
+// On the day clocks go back the window still starts at 18:00 wall time, and
+// the cut-off counts real minutes from there.
+func TestSlotsForDayAcrossDSTChange(t *testing.T) {
+	loc := berlin(t)
+	day := time.Date(2026, 10, 25, 12, 0, 0, 0, loc)
+
+	slots := SlotsForDay(eveningSchedule(), day, loc)
+	if len(slots) != 1 {
+		t.Fatalf("got %d slots on a Sunday, want 1", len(slots))
+	}
+	slot := slots[0]
+	if got := slot.StartsAt.In(loc).Format("15:04 MST"); got != "18:00 CET" {
+		t.Errorf("starts at %s, want 18:00 CET", got)
+	}
+	if got := slot.StartsAt.UTC().Hour(); got != 17 {
+		t.Errorf("starts at %d:00 UTC, want 17:00", got)
+	}
+	if got := slot.StartsAt.Sub(slot.CutoffAt); got != 90*time.Minute {
+		t.Errorf("cut-off is %v before the start, want 1h30m", got)
+	}
+}
+
+func TestValidateScheduleRejectsOverlappingWeekdays(t *testing.T) {
+	schedule := eveningSchedule()
+	// An every-day window already covers Saturday 18:00-20:00.
+	schedule.Windows = append(schedule.Windows, models.DeliverySlotWindow{
+		Weekdays: []int{6}, Start: "18:00", End: "20:00", Capacity: 3,
+	})
+	if err := ValidateSchedule(schedule); err == nil {
+		t.Error("duplicate Saturday window was accepted")
+	}
+	if err := ValidateSchedule(eveningSchedule()); err != nil {
+		t.Errorf("valid schedule: %v", err)
+	}
+}
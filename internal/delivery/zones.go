@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// HasZones reports whether any active delivery zone is defined. Without
+// zones every location is served.
+func HasZones(ctx context.Context, db *mongo.Database) (bool, error) {
+	count, err := db.Collection("delivery_zones").CountDocuments(
+		ctx,
+		bson.M{"isActive": true},
+		options.Count().SetLimit(1),
+	)
+	return count > 0, err
+}
+
+// FindZone returns the active zone containing the point, or nil when none
+// does. Overlapping zones resolve by name so the result is stable.
+func FindZone(ctx context.Context, db *mongo.Database, latitude, longitude float64) (*models.DeliveryZone, error) {
+	var zone models.DeliveryZone
+	err := db.Collection("delivery_zones").FindOne(
+		ctx,
+		bson.M{
+			"isActive": true,
+			"area": bson.M{
+				"$geoIntersects": bson.M{
+					"$geometry": bson.M{
+						"type":        "Point",
+						"coordinates": []float64{longitude, latitude},
+					},
+				},
+			},
+		},
+		options.FindOne().SetSort(bson.D{{Key: "name", Value: 1}}),
+	).Decode(&zone)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
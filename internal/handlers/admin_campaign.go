@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type CampaignCreateRequest struct {
+	Name         string    `json:"name" binding:"required"`
+	Description  string    `json:"description"`
+	DiscountType string    `json:"discountType" binding:"required"`
+	Value        float64   `json:"value" binding:"required"`
+	ProductIDs   []string  `json:"productIds"`
+	Categories   []string  `json:"categories"`
+	StartsAt     time.Time `json:"startsAt" binding:"required"`
+	EndsAt       time.Time `json:"endsAt" binding:"required"`
+	IsActive     *bool     `json:"isActive"`
+}
+
+type CampaignUpdateRequest struct {
+	Name         *string    `json:"name"`
+	Description  *string    `json:"description"`
+	DiscountType *string    `json:"discountType"`
+	Value        *float64   `json:"value"`
+	ProductIDs   *[]string  `json:"productIds"`
+	Categories   *[]string  `json:"categories"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	IsActive     *bool      `json:"isActive"`
+}
+
+/*
+GET /admin/campaigns
+- ?state=active|scheduled|ended
+*/
+func GetAllCampaigns(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+		now := time.Now()
+
+		switch strings.TrimSpace(c.Query("state")) {
+		case "":
+		case "active":
+			filter["isActive"] = true
+			filter["startsAt"] = bson.M{"$lte": now}
+			filter["endsAt"] = bson.M{"$gt": now}
+		case "scheduled":
+			filter["isActive"] = true
+			filter["startsAt"] = bson.M{"$gt": now}
+		case "ended":
+			filter["endsAt"] = bson.M{"$lte": now}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "startsAt", Value: -1}})
+		cursor, err := db.Collection("campaigns").Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		campaigns := []models.Campaign{}
+		if err := cursor.All(ctx, &campaigns); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": campaigns})
+	}
+}
+
+/*
+POST /admin/campaigns
+*/
+func CreateCampaign(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CampaignCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		productIDs, err := parseProductIDList(req.ProductIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
+		campaign := models.Campaign{
+			Name:         strings.TrimSpace(req.Name),
+			Description:  strings.TrimSpace(req.Description),
+			DiscountType: req.DiscountType,
+			Value:        req.Value,
+			ProductIDs:   productIDs,
+			Categories:   trimStringList(req.Categories),
+			StartsAt:     req.StartsAt,
+			EndsAt:       req.EndsAt,
+			IsActive:     true,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if req.IsActive != nil {
+			campaign.IsActive = *req.IsActive
+		}
+
+		if err := validateCampaign(campaign); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("campaigns").InsertOne(ctx, campaign)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		campaign.ID = result.InsertedID.(primitive.ObjectID)
+		c.JSON(http.StatusCreated, campaign)
+	}
+}
+
+/*
+PUT /admin/campaigns/:id
+*/
+func UpdateCampaign(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req CampaignUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var campaign models.Campaign
+		err = db.Collection("campaigns").FindOne(ctx, bson.M{"_id": id}).Decode(&campaign)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		if req.Name != nil {
+			campaign.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			campaign.Description = strings.TrimSpace(*req.Description)
+		}
+		if req.DiscountType != nil {
+			campaign.DiscountType = *req.DiscountType
+		}
+		if req.Value != nil {
+			campaign.Value = *req.Value
+		}
+		if req.ProductIDs != nil {
+			if campaign.ProductIDs, err = parseProductIDList(*req.ProductIDs); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.Categories != nil {
+			campaign.Categories = trimStringList(*req.Categories)
+		}
+		if req.StartsAt != nil {
+			campaign.StartsAt = *req.StartsAt
+		}
+		if req.EndsAt != nil {
+			campaign.EndsAt = *req.EndsAt
+		}
+		if req.IsActive != nil {
+			campaign.IsActive = *req.IsActive
+		}
+
+		if err := validateCampaign(campaign); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		update := bson.M{
+			"name":         campaign.Name,
+			"description":  campaign.Description,
+			"discountType": campaign.DiscountType,
+			"value":        campaign.Value,
+			"productIds":   campaign.ProductIDs,
+			"categories":   campaign.Categories,
+			"startsAt":     campaign.StartsAt,
+			"endsAt":       campaign.EndsAt,
+			"isActive":     campaign.IsActive,
+			"updatedAt":    time.Now(),
+		}
+
+		var updated models.Campaign
+		err = db.Collection("campaigns").FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": update},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/campaigns/:id
+- Soft delete (isActive=false); kampanya anında sona erer
+*/
+func DeleteCampaign(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("campaigns").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func validateCampaign(campaign models.Campaign) error {
+	switch {
+	case campaign.Name == "":
+		return errors.New("name required")
+	case campaign.DiscountType != models.CampaignDiscountPercent && campaign.DiscountType != models.CampaignDiscountFixedPrice:
+		return errors.New("discountType must be percent or fixed_price")
+	case campaign.Value <= 0:
+		return errors.New("value must be greater than zero")
+	case campaign.DiscountType == models.CampaignDiscountPercent && campaign.Value >= 100:
+		return errors.New("percent must be less than 100")
+	case len(campaign.ProductIDs) == 0 && len(campaign.Categories) == 0:
+		return errors.New("productIds or categories required")
+	case !campaign.EndsAt.After(campaign.StartsAt):
+		return errors.New("endsAt must be after startsAt")
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type CouponCreateRequest struct {
+	Code         string     `json:"code" binding:"required"`
+	Description  string     `json:"description"`
+	Type         string     `json:"type" binding:"required"`
+	Value        float64    `json:"value" binding:"required"`
+	MaxDiscount  float64    `json:"maxDiscount"`
+	MinBasket    float64    `json:"minBasket"`
+	ProductIDs   []string   `json:"productIds"`
+	Categories   []string   `json:"categories"`
+	UsageLimit   int        `json:"usageLimit"`
+	PerUserLimit int        `json:"perUserLimit"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	IsActive     *bool      `json:"isActive"`
+}
+
+type CouponUpdateRequest struct {
+	Description  *string    `json:"description"`
+	Type         *string    `json:"type"`
+	Value        *float64   `json:"value"`
+	MaxDiscount  *float64   `json:"maxDiscount"`
+	MinBasket    *float64   `json:"minBasket"`
+	ProductIDs   *[]string  `json:"productIds"`
+	Categories   *[]string  `json:"categories"`
+	UsageLimit   *int       `json:"usageLimit"`
+	PerUserLimit *int       `json:"perUserLimit"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	IsActive     *bool      `json:"isActive"`
+}
+
+/*
+GET /admin/coupons
+- ?isActive=true/false
+- ?search= kod içinde arama
+*/
+func GetAllCoupons(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+
+		if v := strings.TrimSpace(c.Query("isActive")); v != "" {
+			filter["isActive"] = v == "true"
+		}
+		if search := normalizeCouponCode(c.Query("search")); search != "" {
+			filter["code"] = bson.M{"$regex": regexp.QuoteMeta(search)}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+		cursor, err := db.Collection("coupons").Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		coupons := []models.Coupon{}
+		if err := cursor.All(ctx, &coupons); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": coupons})
+	}
+}
+
+/*
+POST /admin/coupons
+- Kod büyük harfe çevrilir, aynı kod tekrar eklenemez
+*/
+func CreateCoupon(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CouponCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		productIDs, err := parseProductIDList(req.ProductIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
+		coupon := models.Coupon{
+			Code:         normalizeCouponCode(req.Code),
+			Description:  strings.TrimSpace(req.Description),
+			Type:         req.Type,
+			Value:        req.Value,
+			MaxDiscount:  req.MaxDiscount,
+			MinBasket:    req.MinBasket,
+			ProductIDs:   productIDs,
+			Categories:   trimStringList(req.Categories),
+			UsageLimit:   req.UsageLimit,
+			PerUserLimit: req.PerUserLimit,
+			StartsAt:     req.StartsAt,
+			EndsAt:       req.EndsAt,
+			IsActive:     true,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if req.IsActive != nil {
+			coupon.IsActive = *req.IsActive
+		}
+
+		if err := validateCoupon(coupon); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("coupons").InsertOne(ctx, coupon)
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "coupon code already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		coupon.ID = result.InsertedID.(primitive.ObjectID)
+		c.JSON(http.StatusCreated, coupon)
+	}
+}
+
+/*
+PUT /admin/coupons/:id
+- Kod değiştirilemez; kullanılmış kuponların geçmiş siparişlerle bağı korunur
+*/
+func UpdateCoupon(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req CouponUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var coupon models.Coupon
+		err = db.Collection("coupons").FindOne(ctx, bson.M{"_id": id}).Decode(&coupon)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		if req.Description != nil {
+			coupon.Description = strings.TrimSpace(*req.Description)
+		}
+		if req.Type != nil {
+			coupon.Type = *req.Type
+		}
+		if req.Value != nil {
+			coupon.Value = *req.Value
+		}
+		if req.MaxDiscount != nil {
+			coupon.MaxDiscount = *req.MaxDiscount
+		}
+		if req.MinBasket != nil {
+			coupon.MinBasket = *req.MinBasket
+		}
+		if req.ProductIDs != nil {
+			if coupon.ProductIDs, err = parseProductIDList(*req.ProductIDs); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.Categories != nil {
+			coupon.Categories = trimStringList(*req.Categories)
+		}
+		if req.UsageLimit != nil {
+			coupon.UsageLimit = *req.UsageLimit
+		}
+		if req.PerUserLimit != nil {
+			coupon.PerUserLimit = *req.PerUserLimit
+		}
+		if req.StartsAt != nil {
+			coupon.StartsAt = req.StartsAt
+		}
+		if req.EndsAt != nil {
+			coupon.EndsAt = req.EndsAt
+		}
+		if req.IsActive != nil {
+			coupon.IsActive = *req.IsActive
+		}
+
+		if err := validateCoupon(coupon); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		update := bson.M{
+			"description":  coupon.Description,
+			"type":         coupon.Type,
+			"value":        coupon.Value,
+			"maxDiscount":  coupon.MaxDiscount,
+			"minBasket":    coupon.MinBasket,
+			"productIds":   coupon.ProductIDs,
+			"categories":   coupon.Categories,
+			"usageLimit":   coupon.UsageLimit,
+			"perUserLimit": coupon.PerUserLimit,
+			"startsAt":     coupon.StartsAt,
+			"endsAt":       coupon.EndsAt,
+			"isActive":     coupon.IsActive,
+			"updatedAt":    time.Now(),
+		}
+
+		var updated models.Coupon
+		err = db.Collection("coupons").FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": update},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/coupons/:id
+- Soft delete (isActive=false); kullanım kayıtları korunur
+*/
+func DeleteCoupon(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("coupons").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "coupon not found"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func validateCoupon(coupon models.Coupon) error {
+	switch {
+	case coupon.Code == "":
+		return errors.New("code required")
+	case coupon.Type != models.CouponTypePercentage && coupon.Type != models.CouponTypeFixed:
+		return errors.New("type must be percentage or fixed")
+	case coupon.Value <= 0:
+		return errors.New("value must be greater than zero")
+	case coupon.Type == models.CouponTypePercentage && coupon.Value > 100:
+		return errors.New("percentage cannot exceed 100")
+	case coupon.MaxDiscount < 0 || coupon.MinBasket < 0:
+		return errors.New("maxDiscount and minBasket cannot be negative")
+	case coupon.UsageLimit < 0 || coupon.PerUserLimit < 0:
+		return errors.New("limits cannot be negative")
+	case coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt):
+		return errors.New("endsAt must be after startsAt")
+	}
+	return nil
+}
+
+func parseProductIDList(values []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		id, err := primitive.ObjectIDFromHex(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errors.New("invalid productIds")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func trimStringList(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
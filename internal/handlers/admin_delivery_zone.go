@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// invalidGeometryCode is Mongo's "Can't extract geo keys" error, raised by the
+// 2dsphere index for geometries that pass our checks but are not valid, such
+// as self-intersecting rings.
+const invalidGeometryCode = 16755
+
+type DeliveryZoneCreateRequest struct {
+	Name     string                   `json:"name" binding:"required"`
+	Area     *models.GeoJSONGeometry  `json:"area" binding:"required"`
+	Pricing  *DeliverySettingsRequest `json:"pricing"`
+	IsActive *bool                    `json:"isActive"`
+}
+
+// DeliveryZoneUpdateRequest replaces the given fields. ClearPricing makes the
+// zone fall back to the default delivery settings.
+type DeliveryZoneUpdateRequest struct {
+	Name         *string                  `json:"name"`
+	Area         *models.GeoJSONGeometry  `json:"area"`
+	Pricing      *DeliverySettingsRequest `json:"pricing"`
+	ClearPricing bool                     `json:"clearPricing"`
+	IsActive     *bool                    `json:"isActive"`
+}
+
+/*
+GET /admin/delivery-zones
+*/
+func GetAllDeliveryZones(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+		cursor, err := db.Collection("delivery_zones").Find(ctx, bson.M{}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		zones := []models.DeliveryZone{}
+		if err := cursor.All(ctx, &zones); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": zones})
+	}
+}
+
+/*
+POST /admin/delivery-zones
+- area: GeoJSON Polygon veya MultiPolygon ([boylam, enlem] sırasıyla)
+- pricing verilmezse genel teslimat ayarları uygulanır
+*/
+func CreateDeliveryZone(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DeliveryZoneCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		area, err := normalizeZoneArea(*req.Area)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
+		zone := models.DeliveryZone{
+			Name:      strings.TrimSpace(req.Name),
+			Area:      area,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if req.IsActive != nil {
+			zone.IsActive = *req.IsActive
+		}
+		if req.Pricing != nil {
+			if zone.Pricing, err = parseDeliveryPricing(*req.Pricing); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if zone.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("delivery_zones").InsertOne(ctx, zone)
+		if isInvalidGeometry(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid area"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		zone.ID = result.InsertedID.(primitive.ObjectID)
+		log.Println("[DELIVERY] [INFO] delivery zone created:", zone.ID.Hex())
+		c.JSON(http.StatusCreated, zone)
+	}
+}
+
+/*
+PUT /admin/delivery-zones/:id
+- clearPricing=true bölgeyi genel teslimat ayarlarına döndürür
+*/
+func UpdateDeliveryZone(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req DeliveryZoneUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		set := bson.M{"updatedAt": time.Now()}
+		unset := bson.M{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "name required"})
+				return
+			}
+			set["name"] = name
+		}
+		if req.Area != nil {
+			area, err := normalizeZoneArea(*req.Area)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			set["area"] = area
+		}
+		if req.ClearPricing {
+			unset["pricing"] = ""
+		} else if req.Pricing != nil {
+			pricing, err := parseDeliveryPricing(*req.Pricing)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			set["pricing"] = pricing
+		}
+		if req.IsActive != nil {
+			set["isActive"] = *req.IsActive
+		}
+
+		update := bson.M{"$set": set}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var updated models.DeliveryZone
+		err = db.Collection("delivery_zones").FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "delivery zone not found"})
+			return
+		}
+		if isInvalidGeometry(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid area"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		log.Println("[DELIVERY] [INFO] delivery zone updated:", id.Hex())
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/delivery-zones/:id
+- Soft delete (isActive=false)
+*/
+func DeleteDeliveryZone(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("delivery_zones").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "delivery zone not found"})
+			return
+		}
+
+		log.Println("[DELIVERY] [INFO] delivery zone deactivated:", id.Hex())
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// normalizeZoneArea checks a Polygon or MultiPolygon and returns it with
+// typed coordinates so Mongo stores plain arrays of doubles.
+func normalizeZoneArea(area models.GeoJSONGeometry) (models.GeoJSONGeometry, error) {
+	raw, err := json.Marshal(area.Coordinates)
+	if err != nil {
+		return models.GeoJSONGeometry{}, errors.New("invalid area coordinates")
+	}
+
+	switch area.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(raw, &polygon); err != nil {
+			return models.GeoJSONGeometry{}, errors.New("invalid area coordinates")
+		}
+		if err := validatePolygon(polygon); err != nil {
+			return models.GeoJSONGeometry{}, err
+		}
+		return models.GeoJSONGeometry{Type: area.Type, Coordinates: polygon}, nil
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(raw, &polygons); err != nil {
+			return models.GeoJSONGeometry{}, errors.New("invalid area coordinates")
+		}
+		if len(polygons) == 0 {
+			return models.GeoJSONGeometry{}, errors.New("area needs at least one polygon")
+		}
+		for _, polygon := range polygons {
+			if err := validatePolygon(polygon); err != nil {
+				return models.GeoJSONGeometry{}, err
+			}
+		}
+		return models.GeoJSONGeometry{Type: area.Type, Coordinates: polygons}, nil
+	}
+	return models.GeoJSONGeometry{}, errors.New("area type must be Polygon or MultiPolygon")
+}
+
+// validatePolygon checks every ring is closed, has at least four positions
+// and only holds [longitude, latitude] pairs in range.
+func validatePolygon(polygon [][][]float64) error {
+	if len(polygon) == 0 {
+		return errors.New("polygon needs at least one ring")
+	}
+	for _, ring := range polygon {
+		if len(ring) < 4 {
+			return errors.New("polygon ring needs at least four positions")
+		}
+		for _, position := range ring {
+			if len(position) != 2 {
+				return errors.New("positions must be [longitude, latitude]")
+			}
+			lng, lat := position[0], position[1]
+			if err := validateCoordinates(&lat, &lng); err != nil {
+				return err
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return errors.New("polygon ring must be closed")
+		}
+	}
+	return nil
+}
+
+func isInvalidGeometry(err error) bool {
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) {
+		for _, e := range writeErr.WriteErrors {
+			if e.Code == invalidGeometryCode {
+				return true
+			}
+		}
+	}
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == invalidGeometryCode
+}
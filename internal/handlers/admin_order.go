@@ -2,15 +2,155 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/payments"
 )
 
+type OrderStatusUpdateRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
+
+type OrderCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+/*
+GET /admin/api/orders
+- Tüm siparişler (admin paneli)
+- Filtreler: status, paymentMethod, from, to, customerType (guest/registered), search (sipariş no/adres)
+- response: data + pagination + summary (durum bazlı adet, ciro)
+*/
+func GetAllOrders(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination params"})
+			return
+		}
+
+		filter, err := buildAdminOrderFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		orders, total, err := findOrdersPage(ctx, db, filter, page, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		summary, err := summarizeOrders(ctx, db, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":       orders,
+			"pagination": paginationResponse(page, limit, total),
+			"summary":    summary,
+		})
+	}
+}
+
+func buildAdminOrderFilter(c *gin.Context) (bson.M, error) {
+	filter := bson.M{"isDeleted": bson.M{"$ne": true}}
+
+	status, err := parseStatusFilter(c.Query("status"))
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		filter["status"] = status
+	}
+
+	if method := strings.ToLower(strings.TrimSpace(c.Query("paymentMethod"))); method != "" {
+		if method != "cash" && method != "card" {
+			return nil, errors.New("invalid paymentMethod")
+		}
+		filter["paymentMethod"] = method
+	}
+
+	createdAt := bson.M{}
+	if from := strings.TrimSpace(c.Query("from")); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			return nil, errors.New("invalid from date")
+		}
+		createdAt["$gte"] = t
+	}
+	if to := strings.TrimSpace(c.Query("to")); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			return nil, errors.New("invalid to date")
+		}
+		createdAt["$lt"] = t
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Query("customerType"))) {
+	case "":
+	case "guest":
+		filter["userId"] = nil
+	case "registered":
+		filter["userId"] = bson.M{"$ne": nil}
+	default:
+		return nil, errors.New("invalid customerType")
+	}
+
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}
+		filter["$or"] = bson.A{
+			bson.M{"number": strings.TrimPrefix(search, "#")},
+			bson.M{"customer.title": pattern},
+			bson.M{"customer.detail": pattern},
+		}
+	}
+
+	return filter, nil
+}
+
+// parseDateParam accepts RFC3339 timestamps or plain YYYY-MM-DD dates. Plain
+// dates used as an upper bound cover the whole day.
+func parseDateParam(value string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+/*
+DELETE /admin/api/orders/:id
+- Soft delete, sipariş arşivlenmiş görünümüne taşınır
+*/
 func DeleteOrder(db *mongo.Database) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -22,13 +162,25 @@ func DeleteOrder(db *mongo.Database) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		result, err := db.Collection("orders").DeleteOne(ctx, bson.M{"_id": orderID})
+		now := time.Now()
+		result, err := db.Collection("orders").UpdateOne(
+			ctx,
+			bson.M{
+				"_id":       orderID,
+				"isDeleted": bson.M{"$ne": true},
+			},
+			bson.M{"$set": bson.M{
+				"isDeleted": true,
+				"deletedAt": now,
+				"updatedAt": now,
+			}},
+		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 			return
 		}
 
-		if result.DeletedCount == 0 {
+		if result.MatchedCount == 0 {
 			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 			return
 		}
@@ -36,3 +188,227 @@ func DeleteOrder(db *mongo.Database) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "order deleted"})
 	}
 }
+
+/*
+GET /admin/api/orders/archived
+- Varsayılan: soft delete edilmiş siparişler
+- ?source=archive → saklama süresi dolup orders_archive'e taşınan siparişler
+- response: data + pagination
+*/
+func GetArchivedOrders(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination params"})
+			return
+		}
+
+		collection := db.Collection("orders")
+		filter := bson.M{"isDeleted": true}
+		switch c.Query("source") {
+		case "", "deleted":
+		case "archive":
+			collection = db.Collection("orders_archive")
+			filter = bson.M{}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		orders, total, err := findOrdersPageIn(ctx, collection, filter, page, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":       orders,
+			"pagination": paginationResponse(page, limit, total),
+		})
+	}
+}
+
+/*
+POST /admin/api/orders/:id/restore
+- Soft delete edilmiş siparişi geri alır
+- orders_archive'deki siparişi orders koleksiyonuna geri taşır
+*/
+func RestoreOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		result, err := db.Collection("orders").UpdateOne(
+			ctx,
+			bson.M{"_id": orderID, "isDeleted": true},
+			bson.M{
+				"$set":   bson.M{"isDeleted": false, "updatedAt": now},
+				"$unset": bson.M{"deletedAt": ""},
+			},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if result.MatchedCount > 0 {
+			c.JSON(http.StatusOK, gin.H{"message": "order restored"})
+			return
+		}
+
+		session, err := db.Client().StartSession()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer session.EndSession(ctx)
+
+		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			var archived bson.M
+			err := db.Collection("orders_archive").FindOne(sessCtx, bson.M{"_id": orderID}).Decode(&archived)
+			if err == mongo.ErrNoDocuments {
+				return nil, errOrderNotFound
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			delete(archived, "archivedAt")
+			delete(archived, "deletedAt")
+			archived["isDeleted"] = false
+			archived["updatedAt"] = now
+
+			if _, err := db.Collection("orders").InsertOne(sessCtx, archived); err != nil {
+				return nil, err
+			}
+			_, err = db.Collection("orders_archive").DeleteOne(sessCtx, bson.M{"_id": orderID})
+			return nil, err
+		})
+		if errors.Is(err, errOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		log.Println("[ORDER] [INFO] order restored from archive:", orderID.Hex())
+		c.JSON(http.StatusOK, gin.H{"message": "order restored"})
+	}
+}
+
+/*
+PATCH /admin/api/orders/:id/status
+- Sadece izin verilen geçişler kabul edilir
+- Her değişiklik statusHistory'e eklenir
+- cancelled/rejected geçişlerinde stok geri yüklenir, ödenmiş kart siparişleri tam iade edilir
+*/
+func UpdateOrderStatus(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req OrderStatusUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		status := strings.ToLower(strings.TrimSpace(req.Status))
+		if !models.IsValidOrderStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		updated, err := transitionOrder(ctx, db, bson.M{"_id": orderID, "isDeleted": bson.M{"$ne": true}}, models.OrderStatusChange{
+			To:        status,
+			ActorType: models.OrderActorAdmin,
+			ActorID:   adminIDFromContext(c),
+			Note:      strings.TrimSpace(req.Note),
+		})
+		if err != nil {
+			respondOrderTransitionError(c, err)
+			return
+		}
+		updated = completePendingRefunds(ctx, db, provider, updated)
+
+		log.Printf("[ORDER] [INFO] order %s status -> %s", orderID.Hex(), updated.Status)
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+POST /admin/api/orders/:id/cancel
+- Teslim edilmemiş siparişi iptal eder, stokları geri yükler
+- Ödenmiş kart siparişinde kalan tutar sağlayıcıdan iade edilir
+*/
+func AdminCancelOrder(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req OrderCancelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		updated, err := transitionOrder(ctx, db, bson.M{"_id": orderID, "isDeleted": bson.M{"$ne": true}}, models.OrderStatusChange{
+			To:        models.OrderStatusCancelled,
+			ActorType: models.OrderActorAdmin,
+			ActorID:   adminIDFromContext(c),
+			Note:      reason,
+		})
+		if err != nil {
+			respondOrderTransitionError(c, err)
+			return
+		}
+		updated = completePendingRefunds(ctx, db, provider, updated)
+
+		log.Println("[ORDER] [INFO] order cancelled by admin:", orderID.Hex())
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// adminIDFromContext returns the admin id stored by AuthGuard, or an empty
+// string when the claims are missing.
+func adminIDFromContext(c *gin.Context) string {
+	value, ok := c.Get("claims")
+	if !ok {
+		return ""
+	}
+	claims, ok := value.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+	"backend/internal/notifications"
+	"backend/internal/payments"
+	"backend/internal/promotions"
+)
+
+type OrderPickSubstituteRequest struct {
+	ProductID string  `json:"productId" binding:"required"`
+	Quantity  float64 `json:"quantity" binding:"required"`
+}
+
+// OrderPickRequest records how one line was packed. Quantity is the amount of
+// the ordered product picked; it is required for short_picked and
+// substituted, and carries the weighed amount of a picked weighed line.
+type OrderPickRequest struct {
+	Status     string                      `json:"status" binding:"required"`
+	Quantity   *float64                    `json:"quantity"`
+	Substitute *OrderPickSubstituteRequest `json:"substitute"`
+	Note       string                      `json:"note"`
+}
+
+// pickableStatuses are the order statuses staff pack orders in.
+var pickableStatuses = []string{models.OrderStatusConfirmed, models.OrderStatusPreparing}
+
+// weighedOverageRatio is how much more than ordered a weighed line may be
+// packed, e.g. a cut of cheese slightly over the asked weight. The overage is
+// not charged.
+const weighedOverageRatio = 0.1
+
+type pickingError struct {
+	Message string
+}
+
+func (e pickingError) Error() string {
+	return e.Message
+}
+
+/*
+PUT /admin/api/orders/:id/picking/:productId
+- Kalemi picked, short_picked (quantity ile) veya substituted (quantity + substitute) olarak işaretler
+- Sipariş kalemi olduğu gibi korunur; toplama bilgisi kalemin picking alanına yazılır
+- Toplama tamamlanana kadar tekrar işaretlenebilir
+*/
+func PickOrderItem(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+		productID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid productId"})
+			return
+		}
+
+		var req OrderPickRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		order, err := loadPickableOrder(ctx, db, orderID)
+		if err != nil {
+			respondPickingError(c, err)
+			return
+		}
+
+		index := -1
+		for i, item := range order.Items {
+			if item.ProductID == productID {
+				index = i
+			}
+		}
+		if index == -1 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		item := order.Items[index]
+
+		picking, err := buildItemPicking(ctx, db, item, req)
+		if err != nil {
+			respondPickingError(c, err)
+			return
+		}
+		picking.PickedBy = adminIDFromContext(c)
+
+		// The picked-at guard keeps a completed picking from being edited.
+		res, err := db.Collection("orders").UpdateOne(
+			ctx,
+			bson.M{"_id": orderID, "pickedAt": bson.M{"$exists": false}, "items.productId": productID},
+			bson.M{"$set": bson.M{"items.$.picking": picking, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if res.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "picking already completed"})
+			return
+		}
+
+		order.Items[index].Picking = &picking
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+/*
+POST /admin/api/orders/:id/picking/complete
+- Tüm kalemler işaretlenmiş olmalı
+- Eksik ve değiştirilen ürünlerin fark tutarı iade olarak kaydedilir (kartla ödenmişse sağlayıcıdan iade)
+- Henüz ödenmemiş siparişte fark "deducted" olarak tutardan düşülür, amountDue güncellenir
+- İkame ürünlerin stoğu düşülür, müşteriye bildirim gönderilir
+*/
+func CompleteOrderPicking(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		order, refund, err := completePicking(ctx, db, orderID, adminIDFromContext(c))
+		if err != nil {
+			var stockErr outOfStockError
+			if errors.As(err, &stockErr) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":     "İkame ürün stokta yok",
+					"productId": stockErr.ProductID.Hex(),
+					"available": stockErr.Available,
+					"requested": stockErr.Requested,
+				})
+				return
+			}
+			respondPickingError(c, err)
+			return
+		}
+
+		if refund != nil && refund.Status == models.RefundStatusPending {
+			var result models.OrderRefund
+			if order, result, err = completeCardRefund(ctx, db, provider, order, *refund); err != nil {
+				log.Println("[PAYMENT] [ERROR] card refund not sent:", err)
+			}
+			refund = &result
+		}
+
+		notifyOrderPicked(ctx, db, order, refund)
+
+		log.Println("[ORDER] [INFO] picking completed:", orderID.Hex())
+		c.JSON(http.StatusOK, gin.H{"order": order, "refund": refund})
+	}
+}
+
+func loadPickableOrder(ctx context.Context, db *mongo.Database, orderID primitive.ObjectID) (models.Order, error) {
+	var order models.Order
+	err := db.Collection("orders").FindOne(ctx, bson.M{
+		"_id":       orderID,
+		"isDeleted": bson.M{"$ne": true},
+	}).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return models.Order{}, errOrderNotFound
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+	if !statusAllowed(pickableStatuses, order.Status) {
+		return models.Order{}, pickingError{Message: "order cannot be picked in status " + order.Status}
+	}
+	if order.PickedAt != nil {
+		return models.Order{}, pickingError{Message: "picking already completed"}
+	}
+	return order, nil
+}
+
+// buildItemPicking validates a pick request against the line. Weighed lines
+// are picked with the weighed quantity, at most weighedOverageRatio over the
+// ordered amount. Weight under the order is refunded when picking is
+// completed; weight over it is recorded as OverQuantity and not charged.
+func buildItemPicking(ctx context.Context, db *mongo.Database, item models.OrderItem, req OrderPickRequest) (models.OrderItemPicking, error) {
+	toPick := models.RoundQuantity(item.Quantity - item.RefundedQuantity)
+	picking := models.OrderItemPicking{
+		Status:   strings.TrimSpace(req.Status),
+		Note:     strings.TrimSpace(req.Note),
+		PickedAt: time.Now(),
+	}
+
+	if req.Quantity != nil && !item.IsWeighed() && !models.IsMultipleOf(*req.Quantity, 1) {
+		return models.OrderItemPicking{}, pickingError{Message: "quantity must be a whole number"}
+	}
+
+	switch picking.Status {
+	case models.PickStatusPicked:
+		picking.PickedQuantity = toPick
+		if req.Quantity != nil && item.IsWeighed() {
+			limit := models.RoundQuantity(toPick * (1 + weighedOverageRatio))
+			if *req.Quantity <= 0 || *req.Quantity > limit {
+				return models.OrderItemPicking{}, pickingError{Message: fmt.Sprintf("weighed quantity must be greater than 0 and at most %s", models.FormatQuantity(limit))}
+			}
+			picking.PickedQuantity = models.RoundQuantity(*req.Quantity)
+			if picking.PickedQuantity > toPick {
+				picking.OverQuantity = models.RoundQuantity(picking.PickedQuantity - toPick)
+				picking.PickedQuantity = toPick
+			}
+		}
+		return picking, nil
+	case models.PickStatusShortPicked, models.PickStatusSubstituted:
+	default:
+		return models.OrderItemPicking{}, pickingError{Message: "status must be picked, short_picked or substituted"}
+	}
+
+	if req.Quantity == nil || *req.Quantity < 0 || *req.Quantity >= toPick {
+		return models.OrderItemPicking{}, pickingError{Message: fmt.Sprintf("quantity must be at least 0 and less than %s", models.FormatQuantity(toPick))}
+	}
+	picking.PickedQuantity = models.RoundQuantity(*req.Quantity)
+
+	if picking.Status == models.PickStatusShortPicked {
+		return picking, nil
+	}
+
+	if req.Substitute == nil {
+		return models.OrderItemPicking{}, pickingError{Message: "substitute productId and quantity required"}
+	}
+	substituteID, err := primitive.ObjectIDFromHex(req.Substitute.ProductID)
+	if err != nil {
+		return models.OrderItemPicking{}, pickingError{Message: "invalid substitute productId"}
+	}
+	if substituteID == item.ProductID {
+		return models.OrderItemPicking{}, pickingError{Message: "substitute must be another product"}
+	}
+
+	var raw bson.M
+	err = db.Collection("products").FindOne(ctx, bson.M{
+		"_id":       substituteID,
+		"isDeleted": bson.M{"$ne": true},
+	}).Decode(&raw)
+	if err == mongo.ErrNoDocuments {
+		return models.OrderItemPicking{}, pickingError{Message: "substitute product not found"}
+	}
+	if err != nil {
+		return models.OrderItemPicking{}, err
+	}
+	product, err := normalizeProductDocument(raw)
+	if err != nil {
+		return models.OrderItemPicking{}, err
+	}
+	campaigns, err := promotions.ActiveCampaigns(ctx, db, time.Now())
+	if err != nil {
+		return models.OrderItemPicking{}, err
+	}
+	if product.HasVariants {
+		return models.OrderItemPicking{}, pickingError{Message: "substitute must be a variant, not a grouped product"}
+	}
+	promotions.ApplyCampaigns(&product, campaigns)
+	if err := product.CheckQuantity(req.Substitute.Quantity); err != nil {
+		return models.OrderItemPicking{}, pickingError{Message: "substitute " + err.Error()}
+	}
+
+	picking.Substitute = &models.OrderItemSubstitute{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Price:     product.Price,
+		Quantity:  req.Substitute.Quantity,
+	}
+	return picking, nil
+}
+
+// completePicking closes picking in one transaction: missing units are
+// marked refunded, substitutes are taken from stock and the price difference
+// is recorded as a refund. Missing units are not restocked; they were not on
+// the shelf. Weighed lines are different: weight left under the order is
+// still on the shelf and goes back to stock, overage is taken from it.
+func completePicking(ctx context.Context, db *mongo.Database, orderID primitive.ObjectID, actorID string) (models.Order, *models.OrderRefund, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var order models.Order
+	var refund *models.OrderRefund
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		refund = nil
+		order, err = loadPickableOrder(sessCtx, db, orderID)
+		if err != nil {
+			return nil, err
+		}
+
+		unpicked := []string{}
+		for _, item := range order.Items {
+			if item.Picking == nil {
+				unpicked = append(unpicked, item.Name)
+			}
+		}
+		if len(unpicked) > 0 {
+			return nil, pickingError{Message: "items not picked: " + strings.Join(unpicked, ", ")}
+		}
+
+		now := time.Now()
+		pending := models.OrderRefund{ID: primitive.NewObjectID()}
+		for i := range order.Items {
+			item := &order.Items[i]
+			if err := adjustWeighedStock(sessCtx, db, order, *item, actorID); err != nil {
+				return nil, err
+			}
+
+			missing := models.RoundQuantity(item.Quantity - item.RefundedQuantity - item.Picking.PickedQuantity)
+			if missing <= 0 {
+				continue
+			}
+
+			missingAmount := roundPrice(chargedUnitPrice(order, *item) * missing)
+			charge := 0.0
+			if sub := item.Picking.Substitute; sub != nil {
+				charge = roundPrice(sub.Price * sub.Quantity)
+				if charge > missingAmount {
+					charge = missingAmount
+				}
+
+				filter := bson.M{
+					"isDeleted": bson.M{"$ne": true},
+					"$expr":     inventory.AvailableAtLeast(sub.Quantity),
+				}
+				matched, err := inventory.AdjustStock(sessCtx, db, sub.ProductID, -sub.Quantity, filter, models.StockMovement{
+					Reason:    models.StockReasonSale,
+					ActorType: models.OrderActorAdmin,
+					ActorID:   actorID,
+					OrderID:   &order.ID,
+					Note:      "substitute for " + item.Name,
+				})
+				if err != nil {
+					return nil, err
+				}
+				if !matched {
+					return nil, outOfStockError{ProductID: sub.ProductID, Requested: sub.Quantity}
+				}
+			}
+
+			item.Picking.PriceDifference = roundPrice(charge - missingAmount)
+			item.RefundedQuantity = models.RoundQuantity(item.RefundedQuantity + missing)
+			pending.Items = append(pending.Items, models.OrderRefundItem{
+				ProductID: item.ProductID,
+				Name:      item.Name,
+				Quantity:  missing,
+				Amount:    -item.Picking.PriceDifference,
+			})
+			pending.Amount += -item.Picking.PriceDifference
+		}
+
+		set := bson.M{"items": order.Items, "pickedAt": now, "updatedAt": now}
+		update := bson.M{"$set": set}
+		order.PickedAt = &now
+
+		pending.Amount = roundPrice(pending.Amount)
+		if pending.Amount > 0 {
+			card := order.PaymentMethod == "card" && order.PaymentStatus == models.PaymentStatusPaid
+			pending.Reason = "picking"
+			pending.ActorID = actorID
+			pending.CreatedAt = now
+			setRefundStatus(order, &pending, card)
+
+			order.RefundedAmount = roundPrice(order.RefundedAmount + pending.Amount)
+			order.Refunds = append(order.Refunds, pending)
+			set["refundedAmount"] = order.RefundedAmount
+			if pending.Status == models.RefundStatusDeducted {
+				set["amountDue"] = deductAmountDue(&order)
+			}
+			update["$push"] = bson.M{"refunds": pending}
+			refund = &pending
+		}
+
+		_, err := db.Collection("orders").UpdateOne(sessCtx, bson.M{"_id": order.ID}, update)
+		return nil, err
+	})
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	return order, refund, nil
+}
+
+// adjustWeighedStock settles the stock of a picked weighed line against the
+// ordered amount the sale already took: weight not packed goes back, overage
+// is taken as well.
+func adjustWeighedStock(ctx context.Context, db *mongo.Database, order models.Order, item models.OrderItem, actorID string) error {
+	if !item.IsWeighed() || item.Picking.Status != models.PickStatusPicked {
+		return nil
+	}
+
+	movement := models.StockMovement{
+		ActorType: models.OrderActorAdmin,
+		ActorID:   actorID,
+		OrderID:   &order.ID,
+	}
+	missing := models.RoundQuantity(item.Quantity - item.RefundedQuantity - item.Picking.PickedQuantity)
+	switch {
+	case missing > 0:
+		movement.Reason = models.StockReasonReturn
+		movement.Note = "weight not picked for " + item.Name
+		_, err := inventory.AdjustStock(ctx, db, item.ProductID, missing, nil, movement)
+		return err
+	case item.Picking.OverQuantity > 0:
+		movement.Reason = models.StockReasonSale
+		movement.Note = "weighed overage for " + item.Name + ", not charged"
+		_, err := inventory.AdjustStock(ctx, db, item.ProductID, -item.Picking.OverQuantity, nil, movement)
+		return err
+	}
+	return nil
+}
+
+// notifyOrderPicked tells a registered customer what was packed. Guests have
+// no inbox; their order shows the picking result.
+func notifyOrderPicked(ctx context.Context, db *mongo.Database, order models.Order, refund *models.OrderRefund) {
+	if order.UserID == nil {
+		return
+	}
+
+	var short, substituted int
+	for _, item := range order.Items {
+		switch item.Picking.Status {
+		case models.PickStatusShortPicked:
+			short++
+		case models.PickStatusSubstituted:
+			substituted++
+		}
+	}
+
+	message := "Siparişiniz hazırlandı."
+	if short > 0 {
+		message += fmt.Sprintf(" %d ürün eksik.", short)
+	}
+	if substituted > 0 {
+		message += fmt.Sprintf(" %d ürün yerine benzeri gönderildi.", substituted)
+	}
+	switch {
+	case refund != nil && refund.Status == models.RefundStatusDeducted:
+		message += fmt.Sprintf(" %.2f TL fark tutardan düşüldü, ödenecek tutar %.2f TL.", refund.Amount, *order.AmountDue)
+	case refund != nil:
+		message += fmt.Sprintf(" %.2f TL fark iade edildi.", refund.Amount)
+	}
+
+	_, err := notifications.Notify(ctx, db, models.Notification{
+		UserID:  *order.UserID,
+		OrderID: &order.ID,
+		Type:    models.NotificationOrderPicked,
+		Title:   "Siparişiniz hazırlandı",
+		Message: message,
+	})
+	if err != nil {
+		log.Println("[ORDER] [ERROR] picking notification failed:", err)
+	}
+}
+
+func respondPickingError(c *gin.Context, err error) {
+	var pickErr pickingError
+	switch {
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	case errors.As(err, &pickErr):
+		c.JSON(http.StatusConflict, gin.H{"error": pickErr.Message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+	}
+}
@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+type OrderRefundItemRequest struct {
+	ProductID string  `json:"productId" binding:"required"`
+	Quantity  float64 `json:"quantity" binding:"required"`
+}
+
+// OrderRefundRequest refunds either the listed quantities or, with Full, the
+// whole remaining order including the delivery fee. Restock defaults to true.
+type OrderRefundRequest struct {
+	Items   []OrderRefundItemRequest `json:"items"`
+	Full    bool                     `json:"full"`
+	Reason  string                   `json:"reason" binding:"required"`
+	Restock *bool                    `json:"restock"`
+}
+
+// refundableStatuses are the order statuses money can be given back in;
+// cancelled and rejected orders already returned their stock.
+var refundableStatuses = []string{
+	models.OrderStatusConfirmed,
+	models.OrderStatusPreparing,
+	models.OrderStatusOutForDelivery,
+	models.OrderStatusDelivered,
+}
+
+type refundError struct {
+	Message string
+}
+
+func (e refundError) Error() string {
+	return e.Message
+}
+
+/*
+POST /admin/api/orders/:id/refunds
+- Tüm siparişi (full=true) veya belirli kalem adetlerini iade eder
+- İade kaydı siparişe eklenir, refundedAmount artar, stok geri yüklenir (restock=false hariç)
+- Kartla ödenmiş siparişlerde ödeme sağlayıcısından iade istenir
+*/
+func RefundOrder(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req OrderRefundRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason required"})
+			return
+		}
+		if req.Full == (len(req.Items) > 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "send either items or full"})
+			return
+		}
+
+		quantities := map[primitive.ObjectID]float64{}
+		for _, item := range req.Items {
+			productID, err := primitive.ObjectIDFromHex(item.ProductID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid productId"})
+				return
+			}
+			if item.Quantity <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be greater than zero"})
+				return
+			}
+			quantities[productID] += item.Quantity
+		}
+
+		restock := req.Restock == nil || *req.Restock
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		order, refund, err := recordRefund(ctx, db, orderID, quantities, req.Full, restock, req.Reason, adminIDFromContext(c))
+		if err != nil {
+			respondRefundError(c, err)
+			return
+		}
+
+		if refund.Status == models.RefundStatusPending {
+			if order, refund, err = completeCardRefund(ctx, db, provider, order, refund); err != nil {
+				log.Println("[PAYMENT] [ERROR] card refund not sent:", err)
+			}
+		}
+
+		log.Printf("[ORDER] [INFO] refund %.2f on order %s: %s", refund.Amount, orderID.Hex(), refund.Status)
+		status := http.StatusCreated
+		if refund.Status == models.RefundStatusFailed {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"refund": refund, "order": order})
+	}
+}
+
+/*
+POST /admin/api/orders/:id/refunds/:refundId/retry
+- Sağlayıcıda başarısız olmuş kart iadesini tekrar dener
+*/
+func RetryOrderRefund(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+		refundID, err := primitive.ObjectIDFromHex(c.Param("refundId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var order models.Order
+		err = db.Collection("orders").FindOne(ctx, bson.M{"_id": orderID}).Decode(&order)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		var refund *models.OrderRefund
+		for i := range order.Refunds {
+			if order.Refunds[i].ID == refundID {
+				refund = &order.Refunds[i]
+			}
+		}
+		if refund == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "refund not found"})
+			return
+		}
+		if refund.Status != models.RefundStatusFailed {
+			c.JSON(http.StatusConflict, gin.H{"error": "only failed refunds can be retried", "status": refund.Status})
+			return
+		}
+
+		updated, result, err := completeCardRefund(ctx, db, provider, order, *refund)
+		if errors.Is(err, errRefundNotClaimed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "refund is already being retried"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		status := http.StatusOK
+		if result.Status == models.RefundStatusFailed {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"refund": result, "order": updated})
+	}
+}
+
+// recordRefund stores the refund on the order in one transaction: the refund
+// record, refunded quantities and amount, and the returned stock. Card
+// refunds are stored pending and completed by completeCardRefund.
+func recordRefund(ctx context.Context, db *mongo.Database, orderID primitive.ObjectID, quantities map[primitive.ObjectID]float64, full, restock bool, reason, actorID string) (models.Order, models.OrderRefund, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return models.Order{}, models.OrderRefund{}, err
+	}
+	defer session.EndSession(ctx)
+
+	var order models.Order
+	var refund models.OrderRefund
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		order = models.Order{}
+		err := db.Collection("orders").FindOne(sessCtx, bson.M{
+			"_id":       orderID,
+			"isDeleted": bson.M{"$ne": true},
+		}).Decode(&order)
+		if err == mongo.ErrNoDocuments {
+			return nil, errOrderNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !statusAllowed(refundableStatuses, order.Status) {
+			return nil, refundError{Message: "order cannot be refunded in status " + order.Status}
+		}
+		card := order.PaymentMethod == "card" && order.PaymentStatus != ""
+		if card && order.PaymentStatus != models.PaymentStatusPaid {
+			return nil, refundError{Message: "card payment is not captured"}
+		}
+
+		if refund, err = buildRefund(&order, quantities, full); err != nil {
+			return nil, err
+		}
+		refund.Reason = reason
+		refund.Restocked = restock
+		refund.ActorID = actorID
+		refund.CreatedAt = time.Now()
+		setRefundStatus(order, &refund, card)
+
+		order.RefundedAmount = roundPrice(order.RefundedAmount + refund.Amount)
+		order.Refunds = append(order.Refunds, refund)
+		set := bson.M{
+			"items":          order.Items,
+			"refundedAmount": order.RefundedAmount,
+			"updatedAt":      refund.CreatedAt,
+		}
+		if refund.Status == models.RefundStatusDeducted {
+			set["amountDue"] = deductAmountDue(&order)
+		}
+		if !card && order.PaymentStatus == models.PaymentStatusPaid && fullyRefunded(order) {
+			order.PaymentStatus = models.PaymentStatusRefunded
+			set["paymentStatus"] = order.PaymentStatus
+		}
+
+		_, err = db.Collection("orders").UpdateOne(
+			sessCtx,
+			bson.M{"_id": order.ID},
+			bson.M{"$set": set, "$push": bson.M{"refunds": refund}},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if restock {
+			for _, item := range refund.Items {
+				_, err := inventory.AdjustStock(sessCtx, db, item.ProductID, item.Quantity, nil, models.StockMovement{
+					Reason:    models.StockReasonReturn,
+					ActorType: models.OrderActorAdmin,
+					ActorID:   actorID,
+					OrderID:   &order.ID,
+					Note:      reason,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return models.Order{}, models.OrderRefund{}, err
+	}
+	return order, refund, nil
+}
+
+// setRefundStatus sets the status a new refund starts in: card refunds wait
+// for the provider, refunds on orders not paid yet are deducted from what is
+// still to be collected, and cash paid back is completed right away.
+func setRefundStatus(order models.Order, refund *models.OrderRefund, card bool) {
+	switch {
+	case card:
+		refund.Status = models.RefundStatusPending
+	case order.PaymentStatus != models.PaymentStatusPaid:
+		refund.Status = models.RefundStatusDeducted
+		refund.CompletedAt = &refund.CreatedAt
+	default:
+		refund.Status = models.RefundStatusCompleted
+		refund.CompletedAt = &refund.CreatedAt
+	}
+}
+
+// deductAmountDue recomputes what is left to collect on an order not paid
+// yet after its refunded amount changed.
+func deductAmountDue(order *models.Order) float64 {
+	due := roundPrice(order.TotalPrice - order.RefundedAmount)
+	if due < 0 {
+		due = 0
+	}
+	order.AmountDue = &due
+	return due
+}
+
+// chargedUnitPrice is what one unit of item was charged: its price less its
+// share of the line's promotion discount and of the coupon, which is spread
+// over the lines by value.
+func chargedUnitPrice(order models.Order, item models.OrderItem) float64 {
+	var linesNet float64
+	for _, line := range order.Items {
+		linesNet += line.Price*line.Quantity - line.Discount
+	}
+	couponFactor := 1.0
+	if linesNet > 0 {
+		couponFactor = (order.Subtotal - order.Discount) / linesNet
+	}
+	return (item.Price*item.Quantity - item.Discount) / item.Quantity * couponFactor
+}
+
+// buildRefund works out the refunded lines and amount and marks the
+// quantities refunded on order. Units are refunded at chargedUnitPrice; the
+// delivery fee is only refunded with a full refund.
+func buildRefund(order *models.Order, quantities map[primitive.ObjectID]float64, full bool) (models.OrderRefund, error) {
+	refund := models.OrderRefund{ID: primitive.NewObjectID()}
+
+	remaining := roundPrice(order.TotalPrice - order.RefundedAmount)
+	for productID := range quantities {
+		found := false
+		for _, item := range order.Items {
+			found = found || item.ProductID == productID
+		}
+		if !found {
+			return models.OrderRefund{}, refundError{Message: "product not in order: " + productID.Hex()}
+		}
+	}
+
+	var itemsTotal float64
+	for i := range order.Items {
+		item := &order.Items[i]
+		left := models.RoundQuantity(item.Quantity - item.RefundedQuantity)
+
+		quantity := quantities[item.ProductID]
+		if full {
+			quantity = left
+		}
+		if quantity == 0 {
+			continue
+		}
+		if quantity > left {
+			return models.OrderRefund{}, refundError{Message: "refund quantity exceeds remaining quantity of " + item.Name}
+		}
+		if !item.IsWeighed() && !models.IsMultipleOf(quantity, 1) {
+			return models.OrderRefund{}, refundError{Message: "refund quantity of " + item.Name + " must be a whole number"}
+		}
+
+		amount := roundPrice(chargedUnitPrice(*order, *item) * quantity)
+		item.RefundedQuantity = models.RoundQuantity(item.RefundedQuantity + quantity)
+		itemsTotal += amount
+		refund.Items = append(refund.Items, models.OrderRefundItem{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Quantity:  quantity,
+			Amount:    amount,
+		})
+	}
+
+	refund.Amount = roundPrice(itemsTotal)
+	if full {
+		refund.Amount = remaining
+		if fee := roundPrice(remaining - itemsTotal); fee > 0 {
+			refund.DeliveryFee = fee
+		}
+	}
+	if refund.Amount > remaining {
+		refund.Amount = remaining
+	}
+	if refund.Amount <= 0 {
+		return models.OrderRefund{}, refundError{Message: "nothing left to refund"}
+	}
+	return refund, nil
+}
+
+// errRefundNotClaimed means another request changed the refund's status
+// first, so it must not be sent to the provider again.
+var errRefundNotClaimed = errors.New("refund not in the expected status")
+
+// completeCardRefund asks the provider to pay back a pending or failed card
+// refund and records the outcome on the order and payment. The refund is
+// first moved to processing from the status it was read in; when that does
+// not match, another request owns it and errRefundNotClaimed is returned
+// without calling the provider.
+func completeCardRefund(ctx context.Context, db *mongo.Database, provider payments.Provider, order models.Order, refund models.OrderRefund) (models.Order, models.OrderRefund, error) {
+	res, err := db.Collection("orders").UpdateOne(
+		ctx,
+		bson.M{
+			"_id":     order.ID,
+			"refunds": bson.M{"$elemMatch": bson.M{"id": refund.ID, "status": refund.Status}},
+		},
+		bson.M{"$set": bson.M{"refunds.$.status": models.RefundStatusProcessing, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return order, refund, err
+	}
+	if res.MatchedCount == 0 {
+		return order, refund, errRefundNotClaimed
+	}
+
+	var payment models.Payment
+	err = db.Collection("payments").FindOne(ctx, bson.M{
+		"orderId": order.ID,
+		"status":  bson.M{"$in": []string{payments.StatusCaptured, payments.StatusRefunded}},
+	}).Decode(&payment)
+	if err == nil && provider == nil {
+		err = errors.New("card payments are not configured")
+	}
+	if err == nil {
+		_, err = provider.Refund(ctx, payment.ProviderPaymentID, refund.Amount)
+	}
+
+	now := time.Now()
+	set := bson.M{"updatedAt": now}
+	if err != nil {
+		log.Println("[PAYMENT] [ERROR] refund failed:", err)
+		refund.Status = models.RefundStatusFailed
+		refund.FailureReason = err.Error()
+		set["refunds.$.status"] = refund.Status
+		set["refunds.$.failureReason"] = refund.FailureReason
+	} else {
+		refund.Status = models.RefundStatusCompleted
+		refund.FailureReason = ""
+		refund.CompletedAt = &now
+		set["refunds.$.status"] = refund.Status
+		set["refunds.$.failureReason"] = ""
+		set["refunds.$.completedAt"] = now
+
+		paymentSet := bson.M{"refundedAmount": roundPrice(payment.RefundedAmount + refund.Amount), "updatedAt": now}
+		if fullyRefunded(order) {
+			order.PaymentStatus = models.PaymentStatusRefunded
+			set["paymentStatus"] = order.PaymentStatus
+			paymentSet["status"] = payments.StatusRefunded
+		}
+		if _, err := db.Collection("payments").UpdateByID(ctx, payment.ID, bson.M{"$set": paymentSet}); err != nil {
+			log.Println("[PAYMENT] [ERROR] record payment refund failed:", err)
+		}
+	}
+
+	_, err = db.Collection("orders").UpdateOne(
+		ctx,
+		bson.M{"_id": order.ID, "refunds.id": refund.ID},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		log.Println("[ORDER] [ERROR] record refund outcome failed:", err)
+	}
+
+	for i := range order.Refunds {
+		if order.Refunds[i].ID == refund.ID {
+			order.Refunds[i] = refund
+		}
+	}
+	return order, refund, nil
+}
+
+func fullyRefunded(order models.Order) bool {
+	return order.RefundedAmount >= order.TotalPrice-priceTolerance/2
+}
+
+func respondRefundError(c *gin.Context, err error) {
+	var refundErr refundError
+	switch {
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	case errors.As(err, &refundErr):
+		c.JSON(http.StatusConflict, gin.H{"error": refundErr.Message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+// couponOrder has a 3-for-2 on apples (10 off), a 3.00 coupon and a 5.00
+// delivery fee. The lines net 30 and the coupon takes 10% of that, so each
+// apple was charged 6.00 and each pear 4.50.
+func couponOrder(apple, pear primitive.ObjectID) models.Order {
+	return models.Order{
+		Items: []models.OrderItem{
+			{ProductID: apple, Name: "Elma", Price: 10, Quantity: 3, Discount: 10},
+			{ProductID: pear, Name: "Armut", Price: 5, Quantity: 2},
+		},
+		Subtotal:    40,
+		Discount:    13,
+		DeliveryFee: 5,
+		TotalPrice:  32,
+	}
+}
+
+// Partial refunds pay back what each unit was charged after promotions and
+// the coupon; the final full refund returns the rest, delivery fee included,
+// so the refunds add up to exactly what the customer paid.
+func TestRefundsAddUpToTheTotal(t *testing.T) {
+	apple, pear := primitive.NewObjectID(), primitive.NewObjectID()
+	order := couponOrder(apple, pear)
+
+	first, err := buildRefund(&order, map[primitive.ObjectID]float64{apple: 1}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Amount != 6 || first.DeliveryFee != 0 {
+		t.Fatalf("one apple: amount=%v fee=%v, want 6 and no fee", first.Amount, first.DeliveryFee)
+	}
+	order.RefundedAmount += first.Amount
+
+	second, err := buildRefund(&order, map[primitive.ObjectID]float64{pear: 1}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Amount != 4.5 {
+		t.Fatalf("one pear: amount=%v, want 4.5", second.Amount)
+	}
+	order.RefundedAmount += second.Amount
+
+	rest, err := buildRefund(&order, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rest.DeliveryFee != 5 {
+		t.Errorf("full refund delivery fee = %v, want 5", rest.DeliveryFee)
+	}
+	if got := roundPrice(first.Amount + second.Amount + rest.Amount); got != order.TotalPrice {
+		t.Errorf("refunds add up to %v, want %v", got, order.TotalPrice)
+	}
+	for _, item := range order.Items {
+		if item.RefundedQuantity != item.Quantity {
+			t.Errorf("%s refunded %v of %v", item.Name, item.RefundedQuantity, item.Quantity)
+		}
+	}
+
+	order.RefundedAmount += rest.Amount
+	if _, err := buildRefund(&order, nil, true); err == nil {
+		t.Error("refunding a fully refunded order succeeded")
+	}
+}
+
+func TestBuildRefundRejectsBadQuantities(t *testing.T) {
+	apple, pear := primitive.NewObjectID(), primitive.NewObjectID()
+	order := couponOrder(apple, pear)
+	order.Items[0].RefundedQuantity = 2
+
+	if _, err := buildRefund(&order, map[primitive.ObjectID]float64{apple: 2}, false); err == nil {
+		t.Error("refunding two apples when one is left succeeded")
+	}
+	if _, err := buildRefund(&order, map[primitive.ObjectID]float64{primitive.NewObjectID(): 1}, false); err == nil {
+		t.Error("refunding a product that is not in the order succeeded")
+	}
+	if order.Items[0].RefundedQuantity != 2 || order.Items[1].RefundedQuantity != 0 {
+		t.Errorf("rejected refunds changed the refunded quantities: %+v", order.Items)
+	}
+}
+
+// Weighed lines are refunded by weight at the charged price per unit; piece
+// lines only in whole units.
+func TestBuildRefundWeighedLines(t *testing.T) {
+	cheese, bread := primitive.NewObjectID(), primitive.NewObjectID()
+	order := models.Order{
+		Items: []models.OrderItem{
+			{ProductID: cheese, Name: "Peynir", Price: 80, Quantity: 0.7, Unit: models.UnitKg},
+			{ProductID: bread, Name: "Ekmek", Price: 10, Quantity: 2},
+		},
+		Subtotal:   76,
+		TotalPrice: 76,
+	}
+
+	if _, err := buildRefund(&order, map[primitive.ObjectID]float64{bread: 0.5}, false); err == nil {
+		t.Error("refunding half a bread succeeded")
+	}
+
+	// 0.1 + 0.2 carries float noise; the remaining 0.4 kg must still be
+	// refundable afterwards.
+	refund, err := buildRefund(&order, map[primitive.ObjectID]float64{cheese: 0.1 + 0.2}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != 24 {
+		t.Errorf("0.3 kg refund = %v, want 24", refund.Amount)
+	}
+	if _, err := buildRefund(&order, map[primitive.ObjectID]float64{cheese: 0.4}, false); err != nil {
+		t.Errorf("refunding the remaining 0.4 kg: %v", err)
+	}
+}
+
+// slowProvider fails every refund; during runs inside the first call, while
+// the refund is with the provider.
+type slowProvider struct {
+	*payments.FakeProvider
+	refunds int
+	during  func()
+}
+
+func (p *slowProvider) Refund(context.Context, string, float64) (payments.Result, error) {
+	p.refunds++
+	if p.refunds == 1 && p.during != nil {
+		p.during()
+	}
+	return payments.Result{}, errors.New("provider down")
+}
+
+// Two admins retrying the same failed refund both read it as failed; while
+// the first retry is with the provider the second must not reach it.
+func TestCompleteCardRefundClaimsTheRefundOnce(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+
+	refund := models.OrderRefund{ID: primitive.NewObjectID(), Amount: 10, Status: models.RefundStatusFailed}
+	order := models.Order{ID: primitive.NewObjectID(), PaymentMethod: "card", TotalPrice: 40, Refunds: []models.OrderRefund{refund}}
+	if _, err := db.Collection("orders").InsertOne(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	payment := models.Payment{ID: primitive.NewObjectID(), OrderID: order.ID, Provider: "fake", ProviderPaymentID: "fake_1", Status: payments.StatusCaptured}
+	if _, err := db.Collection("payments").InsertOne(ctx, payment); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &slowProvider{FakeProvider: payments.NewFakeProvider(payments.Config{})}
+	var concurrentErr error
+	provider.during = func() {
+		_, _, concurrentErr = completeCardRefund(ctx, db, provider, order, refund)
+	}
+
+	_, first, err := completeCardRefund(ctx, db, provider, order, refund)
+	if err != nil || first.Status != models.RefundStatusFailed {
+		t.Fatalf("first retry: status=%s err=%v, want failed and no error", first.Status, err)
+	}
+	if !errors.Is(concurrentErr, errRefundNotClaimed) {
+		t.Errorf("concurrent retry: err = %v, want errRefundNotClaimed", concurrentErr)
+	}
+	if provider.refunds != 1 {
+		t.Errorf("provider asked %d times, want 1", provider.refunds)
+	}
+}
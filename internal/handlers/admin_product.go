@@ -33,9 +33,10 @@ type ProductCreateRequest struct {
 	Description string   `json:"description"`
 	Barcode     string   `json:"barcode"`
 	Brand       string   `json:"brand"`
-	Stock       *int     `json:"stock"`
+	Stock       *float64 `json:"stock"`
 	IsActive    *bool    `json:"isActive"`
 	IsCampaign  *bool    `json:"isCampaign"`
+	productUnitInput
 }
 
 type ProductUpdateRequest struct {
@@ -46,9 +47,19 @@ type ProductUpdateRequest struct {
 	Description *string   `json:"description"`
 	Barcode     *string   `json:"barcode"`
 	Brand       *string   `json:"brand"`
-	Stock       *int      `json:"stock"`
+	Stock       *float64  `json:"stock"`
 	IsActive    *bool     `json:"isActive"`
 	IsCampaign  *bool     `json:"isCampaign"`
+	productUnitInput
+}
+
+// productUnitInput carries the unit fields of product create and update
+// requests. A zero step or limit falls back to the unit's default.
+type productUnitInput struct {
+	Unit         *string  `json:"unit"`
+	QuantityStep *float64 `json:"quantityStep"`
+	MinQuantity  *float64 `json:"minQuantity"`
+	MaxQuantity  *float64 `json:"maxQuantity"`
 }
 
 /* =======================
@@ -73,6 +84,70 @@ func normalizeCategories(values []string) []string {
 	return out
 }
 
+// validate checks the given unit fields. Limits are only compared with each
+// other when both are sent.
+func (in productUnitInput) validate() error {
+	if in.Unit != nil && !models.ValidUnit(strings.TrimSpace(*in.Unit)) {
+		return errors.New("unit must be piece, kg, g or litre")
+	}
+	if in.QuantityStep != nil && *in.QuantityStep < 0 {
+		return errors.New("quantityStep must be zero or greater")
+	}
+	if in.MinQuantity != nil && *in.MinQuantity < 0 {
+		return errors.New("minQuantity must be zero or greater")
+	}
+	if in.MaxQuantity != nil && *in.MaxQuantity < 0 {
+		return errors.New("maxQuantity must be zero or greater")
+	}
+	if in.MinQuantity != nil && in.MaxQuantity != nil && *in.MaxQuantity > 0 && *in.MinQuantity > *in.MaxQuantity {
+		return errors.New("minQuantity must not exceed maxQuantity")
+	}
+	return nil
+}
+
+// apply copies the given unit fields onto product.
+func (in productUnitInput) apply(product *models.Product) {
+	if in.Unit != nil {
+		product.Unit = strings.TrimSpace(*in.Unit)
+	}
+	if in.QuantityStep != nil {
+		product.QuantityStep = *in.QuantityStep
+	}
+	if in.MinQuantity != nil {
+		product.MinQuantity = *in.MinQuantity
+	}
+	if in.MaxQuantity != nil {
+		product.MaxQuantity = *in.MaxQuantity
+	}
+}
+
+// set adds the given unit fields to an update's $set.
+func (in productUnitInput) set(updateSet bson.M) {
+	if in.Unit != nil {
+		updateSet["unit"] = strings.TrimSpace(*in.Unit)
+	}
+	if in.QuantityStep != nil {
+		updateSet["quantityStep"] = *in.QuantityStep
+	}
+	if in.MinQuantity != nil {
+		updateSet["minQuantity"] = *in.MinQuantity
+	}
+	if in.MaxQuantity != nil {
+		updateSet["maxQuantity"] = *in.MaxQuantity
+	}
+}
+
+// errPieceStock is returned for fractional stock on a piece product.
+var errPieceStock = errors.New("stock must be a whole number for piece products")
+
+// checkProductStock rejects fractional stock on products sold by the piece.
+func checkProductStock(product models.Product) error {
+	if !product.IsWeighed() && !models.IsMultipleOf(product.Stock, 1) {
+		return errPieceStock
+	}
+	return nil
+}
+
 /* =======================
    GET (ADMIN) – LIST
 ======================= */
@@ -188,6 +263,11 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
+			if err := input.Unit.validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
 			isActive := true
 			if input.IsActiveSet {
 				isActive = input.IsActive
@@ -229,9 +309,14 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 				IsDeleted:   false,
 				CreatedAt:   now,
 			}
+			input.Unit.apply(&product)
+			if err := checkProductStock(product); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 
 			log.Printf("CreateProduct inserting product: %+v", product)
-			res, err := db.Collection("products").InsertOne(context.Background(), product)
+			productID, err := insertProductWithLedger(context.Background(), db, product, adminIDFromContext(c))
 			if err != nil {
 				log.Println("CreateProduct insert error:", err)
 				if mongo.IsDuplicateKeyError(err) {
@@ -244,8 +329,8 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
-			product.ID = res.InsertedID.(primitive.ObjectID)
-			log.Println("CreateProduct insert success:", res.InsertedID)
+			product.ID = productID
+			log.Println("CreateProduct insert success:", productID.Hex())
 			c.JSON(http.StatusCreated, product)
 			return
 		}
@@ -300,6 +385,12 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		if err := req.productUnitInput.validate(); err != nil {
+			log.Println("CreateProduct RETURN 400:", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		isActive := true
 		if req.IsActive != nil {
 			isActive = *req.IsActive
@@ -331,9 +422,15 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			IsDeleted:   false,
 			CreatedAt:   now,
 		}
+		req.productUnitInput.apply(&product)
+		if err := checkProductStock(product); err != nil {
+			log.Println("CreateProduct RETURN 400:", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		log.Printf("CreateProduct inserting product: %+v", product)
-		res, err := db.Collection("products").InsertOne(context.Background(), product)
+		productID, err := insertProductWithLedger(context.Background(), db, product, adminIDFromContext(c))
 		if err != nil {
 			log.Println("CreateProduct insert error:", err)
 			if mongo.IsDuplicateKeyError(err) {
@@ -346,8 +443,8 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		product.ID = res.InsertedID.(primitive.ObjectID)
-		log.Println("CreateProduct insert success:", res.InsertedID)
+		product.ID = productID
+		log.Println("CreateProduct insert success:", productID.Hex())
 		c.JSON(http.StatusCreated, product)
 	}
 }
@@ -440,6 +537,11 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			if input.IsCampaignSet {
 				updateSet["isCampaign"] = input.IsCampaign
 			}
+			if err := input.Unit.validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			input.Unit.set(updateSet)
 
 			if len(updateSet) == 0 && len(updateUnset) == 0 {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
@@ -454,17 +556,18 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				update["$unset"] = updateUnset
 			}
 
-			result, err := db.Collection("products").UpdateOne(
-				context.Background(),
-				bson.M{
-					"_id":       id,
-					"isDeleted": bson.M{"$ne": true},
-				},
-				update,
-			)
+			matched, err := updateProductWithLedger(context.Background(), db, id, update, adminIDFromContext(c))
 
 			if err != nil {
 				log.Println("UpdateProduct update error:", err)
+				if errors.Is(err, errParentStock) {
+					c.JSON(http.StatusConflict, gin.H{"error": "stock is managed on the variants"})
+					return
+				}
+				if errors.Is(err, errPieceStock) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
 				if mongo.IsDuplicateKeyError(err) {
 					log.Println("UpdateProduct RETURN 409:", err)
 					c.JSON(http.StatusConflict, gin.H{"error": "barcode already exists"})
@@ -475,9 +578,9 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("UpdateProduct update result: matched=%d modified=%d", result.MatchedCount, result.ModifiedCount)
+			log.Printf("UpdateProduct update result: matched=%d", matched)
 
-			if result.MatchedCount == 0 {
+			if matched == 0 {
 				log.Println("UpdateProduct RETURN 404:", "product not found")
 				c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
 				return
@@ -504,7 +607,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
-			updated.InStock = updated.Stock > 0
+			updated.InStock = updated.AvailableStock() > 0
 			c.JSON(http.StatusOK, updated)
 			return
 		}
@@ -599,6 +702,12 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		if req.IsCampaign != nil {
 			updateSet["isCampaign"] = *req.IsCampaign
 		}
+		if err := req.productUnitInput.validate(); err != nil {
+			log.Println("UpdateProduct RETURN 400:", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.productUnitInput.set(updateSet)
 
 		if len(updateSet) == 0 && len(updateUnset) == 0 {
 			log.Println("UpdateProduct RETURN 400:", "no fields to update")
@@ -615,17 +724,19 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		}
 		log.Printf("UpdateProduct update document: %+v", update)
 
-		result, err := db.Collection("products").UpdateOne(
-			context.Background(),
-			bson.M{
-				"_id":       id,
-				"isDeleted": bson.M{"$ne": true},
-			},
-			update,
-		)
+		matched, err := updateProductWithLedger(context.Background(), db, id, update, adminIDFromContext(c))
 
 		if err != nil {
 			log.Println("UpdateProduct update error:", err)
+			if errors.Is(err, errParentStock) {
+				c.JSON(http.StatusConflict, gin.H{"error": "stock is managed on the variants"})
+				return
+			}
+			if errors.Is(err, errPieceStock) {
+				log.Println("UpdateProduct RETURN 400:", err)
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			if mongo.IsDuplicateKeyError(err) {
 				log.Println("UpdateProduct RETURN 409:", err)
 				c.JSON(http.StatusConflict, gin.H{"error": "barcode already exists"})
@@ -636,9 +747,9 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		log.Printf("UpdateProduct update result: matched=%d modified=%d", result.MatchedCount, result.ModifiedCount)
+		log.Printf("UpdateProduct update result: matched=%d", matched)
 
-		if result.MatchedCount == 0 {
+		if matched == 0 {
 			log.Println("UpdateProduct RETURN 404:", "product not found")
 			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
 			return
@@ -665,7 +776,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		updated.InStock = updated.Stock > 0
+		updated.InStock = updated.AvailableStock() > 0
 		c.JSON(http.StatusOK, updated)
 	}
 }
@@ -707,6 +818,81 @@ func DeleteProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		// A grouped product takes its variants with it.
+		_, err = db.Collection("products").UpdateMany(
+			context.Background(),
+			bson.M{
+				"parentId":  id,
+				"isDeleted": bson.M{"$ne": true},
+			},
+			bson.M{"$set": bson.M{
+				"isDeleted": true,
+				"deletedAt": now,
+				"isActive":  false,
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "product deleted"})
 	}
 }
+
+/* =======================
+   STOCK HISTORY
+======================= */
+
+func GetProductStockHistory(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination params"})
+			return
+		}
+
+		filter := bson.M{"productId": id}
+		if reason := strings.TrimSpace(c.Query("reason")); reason != "" {
+			filter["reason"] = reason
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		total, err := db.Collection("stock_movements").CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		opts := options.Find().
+			SetSkip((page - 1) * limit).
+			SetLimit(limit).
+			SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+		cursor, err := db.Collection("stock_movements").Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		movements := make([]models.StockMovement, 0)
+		if err := cursor.All(ctx, &movements); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":       movements,
+			"pagination": paginationResponse(page, limit, total),
+		})
+	}
+}
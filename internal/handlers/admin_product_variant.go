@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type ProductVariantCreateRequest struct {
+	VariantName string   `json:"variantName" binding:"required"`
+	Name        string   `json:"name"`
+	Price       float64  `json:"price" binding:"required"`
+	Stock       *float64 `json:"stock"`
+	Barcode     string   `json:"barcode"`
+	ImageURL    string   `json:"imageUrl"`
+	IsActive    *bool    `json:"isActive"`
+	productUnitInput
+}
+
+/*
+GET /admin/api/products/:id/variants
+- Ürünün silinmemiş varyantları (eklenme sırasıyla)
+*/
+func GetProductVariants(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cursor, err := db.Collection("products").Find(
+			ctx,
+			bson.M{"parentId": id, "isDeleted": bson.M{"$ne": true}},
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		variants, err := decodeProducts(ctx, cursor)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": variants})
+	}
+}
+
+/*
+POST /admin/api/products/:id/variants
+- Varyant ayrı bir ürün kaydıdır: kendi fiyatı, stoğu, barkodu ve görseli olur
+- Kategori, marka, açıklama ve birim ayarları ana üründen kopyalanır (birim alanları ezilebilir)
+- İlk varyant eklenirken ana ürünün stoğu 0 olmalı; ana ürün artık tek başına satılmaz
+*/
+func CreateProductVariant(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req ProductVariantCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		variantName := strings.TrimSpace(req.VariantName)
+		if variantName == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "variantName required"})
+			return
+		}
+		if req.Price <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid price"})
+			return
+		}
+		if req.Stock == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "stock required"})
+			return
+		}
+		if *req.Stock < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "stock must be zero or greater"})
+			return
+		}
+		if err := req.productUnitInput.validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		variant, err := createProductVariant(ctx, db, parentID, req, variantName, adminIDFromContext(c))
+		if err != nil {
+			var variantErr productVariantError
+			switch {
+			case errors.As(err, &variantErr):
+				c.JSON(variantErr.Status, gin.H{"error": variantErr.Message})
+			case mongo.IsDuplicateKeyError(err):
+				c.JSON(http.StatusConflict, gin.H{"error": "barcode already exists"})
+			default:
+				log.Println("CreateProductVariant insert error:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			}
+			return
+		}
+
+		log.Println("CreateProductVariant insert success:", variant.ID.Hex())
+		c.JSON(http.StatusCreated, variant)
+	}
+}
+
+type productVariantError struct {
+	Status  int
+	Message string
+}
+
+func (e productVariantError) Error() string {
+	return e.Message
+}
+
+// createProductVariant checks the parent, inserts the variant with its
+// initial stock movement and marks the parent as grouping variants in one
+// transaction. Setting hasVariants on every call makes a concurrent stock
+// change on the parent conflict with it.
+func createProductVariant(ctx context.Context, db *mongo.Database, parentID primitive.ObjectID, req ProductVariantCreateRequest, variantName, actorID string) (models.Product, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer session.EndSession(ctx)
+
+	var variant models.Product
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		var parent models.Product
+		err := db.Collection("products").FindOne(sessCtx, bson.M{
+			"_id":       parentID,
+			"isDeleted": bson.M{"$ne": true},
+		}).Decode(&parent)
+		if err == mongo.ErrNoDocuments {
+			return nil, productVariantError{Status: http.StatusNotFound, Message: "product not found"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != nil {
+			return nil, productVariantError{Status: http.StatusBadRequest, Message: "variants cannot have variants"}
+		}
+		// Stock left on the parent could never be sold once it groups
+		// variants.
+		if !parent.HasVariants && parent.Stock > 0 {
+			return nil, productVariantError{Status: http.StatusConflict, Message: "parent stock must be zero before adding variants"}
+		}
+
+		variant = newProductVariant(parent, req, variantName)
+		if err := checkProductStock(variant); err != nil {
+			return nil, productVariantError{Status: http.StatusBadRequest, Message: err.Error()}
+		}
+
+		if variant.ID, err = insertProductInTx(sessCtx, db, variant, actorID); err != nil {
+			return nil, err
+		}
+
+		_, err = db.Collection("products").UpdateOne(sessCtx,
+			bson.M{"_id": parent.ID},
+			bson.M{"$set": bson.M{"hasVariants": true}},
+		)
+		return nil, err
+	})
+	if err != nil {
+		return models.Product{}, err
+	}
+	return variant, nil
+}
+
+// newProductVariant builds a variant of parent, copying what variants share.
+func newProductVariant(parent models.Product, req ProductVariantCreateRequest, variantName string) models.Product {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = parent.Name + " " + variantName
+	}
+	imageURL := strings.TrimSpace(req.ImageURL)
+	if imageURL == "" {
+		imageURL = parent.ImageURL
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	variant := models.Product{
+		Name:         name,
+		Price:        req.Price,
+		Category:     parent.Category,
+		ImageURL:     imageURL,
+		Description:  parent.Description,
+		Barcode:      strings.TrimSpace(req.Barcode),
+		Brand:        parent.Brand,
+		Stock:        *req.Stock,
+		InStock:      *req.Stock > 0,
+		IsActive:     isActive,
+		CreatedAt:    time.Now(),
+		Unit:         parent.Unit,
+		QuantityStep: parent.QuantityStep,
+		MinQuantity:  parent.MinQuantity,
+		MaxQuantity:  parent.MaxQuantity,
+		ParentID:     &parent.ID,
+		VariantName:  variantName,
+	}
+	req.productUnitInput.apply(&variant)
+	return variant
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type promotionBundleItemRequest struct {
+	ProductID string `json:"productId" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+type PromotionCreateRequest struct {
+	Name        string                       `json:"name" binding:"required"`
+	Description string                       `json:"description"`
+	Type        string                       `json:"type" binding:"required"`
+	BuyQuantity int                          `json:"buyQuantity"`
+	PayQuantity int                          `json:"payQuantity"`
+	ProductIDs  []string                     `json:"productIds"`
+	Categories  []string                     `json:"categories"`
+	BundleItems []promotionBundleItemRequest `json:"bundleItems"`
+	BundlePrice float64                      `json:"bundlePrice"`
+	StartsAt    time.Time                    `json:"startsAt" binding:"required"`
+	EndsAt      time.Time                    `json:"endsAt" binding:"required"`
+	IsActive    *bool                        `json:"isActive"`
+}
+
+type PromotionUpdateRequest struct {
+	Name        *string                       `json:"name"`
+	Description *string                       `json:"description"`
+	BuyQuantity *int                          `json:"buyQuantity"`
+	PayQuantity *int                          `json:"payQuantity"`
+	ProductIDs  *[]string                     `json:"productIds"`
+	Categories  *[]string                     `json:"categories"`
+	BundleItems *[]promotionBundleItemRequest `json:"bundleItems"`
+	BundlePrice *float64                      `json:"bundlePrice"`
+	StartsAt    *time.Time                    `json:"startsAt"`
+	EndsAt      *time.Time                    `json:"endsAt"`
+	IsActive    *bool                         `json:"isActive"`
+}
+
+/*
+GET /admin/promotions
+- ?type=multi_buy|bundle
+- ?isActive=true/false
+*/
+func GetAllPromotions(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+
+		if v := strings.TrimSpace(c.Query("type")); v != "" {
+			filter["type"] = v
+		}
+		if v := strings.TrimSpace(c.Query("isActive")); v != "" {
+			filter["isActive"] = v == "true"
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "startsAt", Value: -1}})
+		cursor, err := db.Collection("promotions").Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		list := []models.Promotion{}
+		if err := cursor.All(ctx, &list); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": list})
+	}
+}
+
+/*
+POST /admin/promotions
+- multi_buy: buyQuantity + payQuantity + productIds/categories ("3 al 2 öde")
+- bundle: bundleItems + bundlePrice
+*/
+func CreatePromotion(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PromotionCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		productIDs, err := parseProductIDList(req.ProductIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		bundleItems, err := parseBundleItems(req.BundleItems)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
+		promotion := models.Promotion{
+			Name:        strings.TrimSpace(req.Name),
+			Description: strings.TrimSpace(req.Description),
+			Type:        req.Type,
+			BuyQuantity: req.BuyQuantity,
+			PayQuantity: req.PayQuantity,
+			ProductIDs:  productIDs,
+			Categories:  trimStringList(req.Categories),
+			BundleItems: bundleItems,
+			BundlePrice: req.BundlePrice,
+			StartsAt:    req.StartsAt,
+			EndsAt:      req.EndsAt,
+			IsActive:    true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if req.IsActive != nil {
+			promotion.IsActive = *req.IsActive
+		}
+
+		if err := validatePromotion(promotion); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("promotions").InsertOne(ctx, promotion)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		promotion.ID = result.InsertedID.(primitive.ObjectID)
+		c.JSON(http.StatusCreated, promotion)
+	}
+}
+
+/*
+PUT /admin/promotions/:id
+- type değiştirilemez
+*/
+func UpdatePromotion(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req PromotionUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var promotion models.Promotion
+		err = db.Collection("promotions").FindOne(ctx, bson.M{"_id": id}).Decode(&promotion)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		if req.Name != nil {
+			promotion.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			promotion.Description = strings.TrimSpace(*req.Description)
+		}
+		if req.BuyQuantity != nil {
+			promotion.BuyQuantity = *req.BuyQuantity
+		}
+		if req.PayQuantity != nil {
+			promotion.PayQuantity = *req.PayQuantity
+		}
+		if req.ProductIDs != nil {
+			if promotion.ProductIDs, err = parseProductIDList(*req.ProductIDs); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.Categories != nil {
+			promotion.Categories = trimStringList(*req.Categories)
+		}
+		if req.BundleItems != nil {
+			if promotion.BundleItems, err = parseBundleItems(*req.BundleItems); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.BundlePrice != nil {
+			promotion.BundlePrice = *req.BundlePrice
+		}
+		if req.StartsAt != nil {
+			promotion.StartsAt = *req.StartsAt
+		}
+		if req.EndsAt != nil {
+			promotion.EndsAt = *req.EndsAt
+		}
+		if req.IsActive != nil {
+			promotion.IsActive = *req.IsActive
+		}
+
+		if err := validatePromotion(promotion); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		update := bson.M{
+			"name":        promotion.Name,
+			"description": promotion.Description,
+			"buyQuantity": promotion.BuyQuantity,
+			"payQuantity": promotion.PayQuantity,
+			"productIds":  promotion.ProductIDs,
+			"categories":  promotion.Categories,
+			"bundleItems": promotion.BundleItems,
+			"bundlePrice": promotion.BundlePrice,
+			"startsAt":    promotion.StartsAt,
+			"endsAt":      promotion.EndsAt,
+			"isActive":    promotion.IsActive,
+			"updatedAt":   time.Now(),
+		}
+
+		var updated models.Promotion
+		err = db.Collection("promotions").FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": update},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/promotions/:id
+- Soft delete (isActive=false)
+*/
+func DeletePromotion(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("promotions").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func validatePromotion(promotion models.Promotion) error {
+	if promotion.Name == "" {
+		return errors.New("name required")
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+
+	switch promotion.Type {
+	case models.PromotionMultiBuy:
+		if promotion.PayQuantity < 1 || promotion.BuyQuantity <= promotion.PayQuantity {
+			return errors.New("buyQuantity must be greater than payQuantity and payQuantity at least 1")
+		}
+		if len(promotion.ProductIDs) == 0 && len(promotion.Categories) == 0 {
+			return errors.New("productIds or categories required")
+		}
+	case models.PromotionBundle:
+		if len(promotion.BundleItems) < 2 {
+			return errors.New("bundle needs at least two items")
+		}
+		if promotion.BundlePrice <= 0 {
+			return errors.New("bundlePrice must be greater than zero")
+		}
+	default:
+		return errors.New("type must be multi_buy or bundle")
+	}
+	return nil
+}
+
+func parseBundleItems(values []promotionBundleItemRequest) ([]models.PromotionBundleItem, error) {
+	items := make([]models.PromotionBundleItem, 0, len(values))
+	seen := map[primitive.ObjectID]bool{}
+	for _, value := range values {
+		id, err := primitive.ObjectIDFromHex(strings.TrimSpace(value.ProductID))
+		if err != nil {
+			return nil, errors.New("invalid bundleItems productId")
+		}
+		if value.Quantity <= 0 {
+			return nil, errors.New("bundleItems quantity must be greater than zero")
+		}
+		if seen[id] {
+			return nil, errors.New("bundleItems products must be unique")
+		}
+		seen[id] = true
+		items = append(items, models.PromotionBundleItem{ProductID: id, Quantity: value.Quantity})
+	}
+	return items, nil
+}
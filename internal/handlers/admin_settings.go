@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/models"
+)
+
+type DeliverySettingsRequest struct {
+	MinOrderAmount        *float64 `json:"minOrderAmount" binding:"required"`
+	Fee                   *float64 `json:"fee" binding:"required"`
+	FreeDeliveryThreshold *float64 `json:"freeDeliveryThreshold" binding:"required"`
+}
+
+/*
+GET /admin/api/settings/delivery
+- Kayıt yoksa tüm değerler 0 döner (ücretsiz teslimat, minimum yok)
+*/
+func GetDeliverySettings(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		settings, err := delivery.LoadSettings(ctx, db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+/*
+PUT /admin/api/settings/delivery
+- Üç alan da zorunlu; 0 kuralı kapatır
+*/
+func UpdateDeliverySettings(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DeliverySettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		pricing, err := parseDeliveryPricing(req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		settings, err := delivery.SaveSettings(ctx, db, *pricing, adminIDFromContext(c))
+		if err != nil {
+			log.Println("[SETTINGS] [ERROR] save delivery settings failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		log.Printf("[SETTINGS] [INFO] delivery settings updated: min=%.2f fee=%.2f free=%.2f", pricing.MinOrderAmount, pricing.Fee, pricing.FreeDeliveryThreshold)
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+type DeliverySlotScheduleRequest struct {
+	Windows   []models.DeliverySlotWindow `json:"windows"`
+	Holidays  []string                    `json:"holidays"`
+	DaysAhead int                         `json:"daysAhead"`
+	Required  bool                        `json:"required"`
+}
+
+/*
+GET /admin/api/settings/delivery-slots
+- Kayıt yoksa boş program döner (teslimat zamanı seçilmez)
+*/
+func GetDeliverySlotSchedule(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		schedule, err := delivery.LoadSlotSchedule(ctx, db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if schedule.Windows == nil {
+			schedule.Windows = []models.DeliverySlotWindow{}
+		}
+		if schedule.Holidays == nil {
+			schedule.Holidays = []string{}
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+/*
+PUT /admin/api/settings/delivery-slots
+- Programın tamamını değiştirir; mevcut rezervasyonlar korunur
+*/
+func UpdateDeliverySlotSchedule(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DeliverySlotScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		schedule := models.DeliverySlotSchedule{
+			Windows:   req.Windows,
+			Holidays:  trimStringList(req.Holidays),
+			DaysAhead: req.DaysAhead,
+			Required:  req.Required,
+		}
+		if schedule.Windows == nil {
+			schedule.Windows = []models.DeliverySlotWindow{}
+		}
+		for i := range schedule.Windows {
+			schedule.Windows[i].Start = strings.TrimSpace(schedule.Windows[i].Start)
+			schedule.Windows[i].End = strings.TrimSpace(schedule.Windows[i].End)
+		}
+		if err := delivery.ValidateSchedule(schedule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		saved, err := delivery.SaveSlotSchedule(ctx, db, schedule, adminIDFromContext(c))
+		if err != nil {
+			log.Println("[SETTINGS] [ERROR] save delivery slot schedule failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		log.Printf("[SETTINGS] [INFO] delivery slot schedule updated: windows=%d holidays=%d", len(saved.Windows), len(saved.Holidays))
+		c.JSON(http.StatusOK, saved)
+	}
+}
+
+// parseDeliveryPricing validates the amounts of a delivery pricing request.
+func parseDeliveryPricing(req DeliverySettingsRequest) (*models.DeliveryPricing, error) {
+	pricing := models.DeliveryPricing{
+		MinOrderAmount:        *req.MinOrderAmount,
+		Fee:                   *req.Fee,
+		FreeDeliveryThreshold: *req.FreeDeliveryThreshold,
+	}
+	if pricing.MinOrderAmount < 0 || pricing.Fee < 0 || pricing.FreeDeliveryThreshold < 0 {
+		return nil, errors.New("amounts cannot be negative")
+	}
+	return &pricing, nil
+}
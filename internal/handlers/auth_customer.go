@@ -36,9 +36,10 @@ type RegisterRequest struct {
 }
 
 type RegisterUserRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Name     string `json:"name" binding:"required"`
+	Email     string `json:"email" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	CartToken string `json:"cartToken"`
 }
 
 type LoginResponseUser struct {
@@ -49,8 +50,9 @@ type LoginResponseUser struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email     string `json:"email" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	CartToken string `json:"cartToken"`
 }
 
 type RefreshRequest struct {
@@ -181,14 +183,18 @@ func Login(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Dura
 			}
 
 			log.Println("[AUTH] [INFO] user login succeeded:", user.Email)
-			c.JSON(http.StatusOK, gin.H{
+			resp := gin.H{
 				"accessToken": accessToken,
 				"user": gin.H{
 					"id":    user.ID.Hex(),
 					"name":  user.Name,
 					"email": user.Email,
 				},
-			})
+			}
+			if strings.TrimSpace(req.CartToken) != "" {
+				resp["cartMerged"] = mergeCartOnAuth(c, db, req.CartToken, jwtSecret, user.ID)
+			}
+			c.JSON(http.StatusOK, resp)
 			return
 		} else if err != mongo.ErrNoDocuments {
 			log.Println("[AUTH] [ERROR] login user lookup failed:", err)
@@ -451,14 +457,18 @@ func registerUser(c *gin.Context, db *mongo.Database, req RegisterUserRequest, j
 	}
 
 	log.Println("[AUTH] [INFO] user registered:", email)
-	c.JSON(http.StatusCreated, gin.H{
+	resp := gin.H{
 		"accessToken": accessToken,
 		"user": gin.H{
 			"id":    id.Hex(),
 			"name":  name,
 			"email": email,
 		},
-	})
+	}
+	if strings.TrimSpace(req.CartToken) != "" {
+		resp["cartMerged"] = mergeCartOnAuth(c, db, req.CartToken, jwtSecret, id)
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 func issueUserToken(userID primitive.ObjectID, email, secret string, accessTTL time.Duration) (string, error) {
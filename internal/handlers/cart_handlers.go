@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+type cartItemRequest struct {
+	ProductID string  `json:"productId" binding:"required"`
+	Quantity  float64 `json:"quantity" binding:"required"`
+}
+
+type cartQuantityRequest struct {
+	Quantity *float64 `json:"quantity" binding:"required"`
+}
+
+// cartOwnerResolver finds the cart owner for a request. create asks guest
+// resolvers to start a new cart when the request carries no token. It writes
+// the error response itself and returns false on failure.
+type cartOwnerResolver func(c *gin.Context, create bool) (cartOwner, bool)
+
+// The handlers below are shared by /user/cart and the guest /cart routes.
+
+func getCartHandler(db *mongo.Database, resolve cartOwnerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		owner, ok := resolve(c, false)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cart, err := loadCart(ctx, db, owner)
+		if err != nil {
+			log.Println("[CART] [ERROR] load cart failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		respondCart(c, ctx, db, owner, cart)
+	}
+}
+
+func addCartItemHandler(db *mongo.Database, resolve cartOwnerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req cartItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+
+		productID, err := primitive.ObjectIDFromHex(req.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid productId"})
+			return
+		}
+		if req.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be greater than zero"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		product, err := loadCartProduct(ctx, db, productID)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if product.HasVariants {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "variant required", "productId": product.ID.Hex()})
+			return
+		}
+
+		owner, ok := resolve(c, true)
+		if !ok {
+			return
+		}
+
+		cart, err := loadCart(ctx, db, owner)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		quantity := models.RoundQuantity(cartLineQuantity(cart, productID) + req.Quantity)
+		if err := checkCartQuantity(product, quantity); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		cart, err = saveCartItems(ctx, db, owner, setCartItemQuantity(cart.Items, productID, quantity))
+		if err != nil {
+			log.Println("[CART] [ERROR] add item failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		respondCart(c, ctx, db, owner, cart)
+	}
+}
+
+func setCartItemQuantityHandler(db *mongo.Database, resolve cartOwnerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid productId"})
+			return
+		}
+
+		var req cartQuantityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+		if *req.Quantity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be zero or greater"})
+			return
+		}
+
+		updateCartLine(c, db, resolve, productID, *req.Quantity)
+	}
+}
+
+func removeCartItemHandler(db *mongo.Database, resolve cartOwnerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid productId"})
+			return
+		}
+
+		updateCartLine(c, db, resolve, productID, 0)
+	}
+}
+
+func clearCartHandler(db *mongo.Database, resolve cartOwnerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		owner, ok := resolve(c, false)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cart := models.Cart{Items: []models.CartItem{}}
+		if owner.filter != nil {
+			var err error
+			cart, err = saveCartItems(ctx, db, owner, []models.CartItem{})
+			if err != nil {
+				log.Println("[CART] [ERROR] clear cart failed:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+				return
+			}
+		}
+
+		respondCart(c, ctx, db, owner, cart)
+	}
+}
+
+// updateCartLine sets an existing cart line to quantity; zero removes it.
+func updateCartLine(c *gin.Context, db *mongo.Database, resolve cartOwnerResolver, productID primitive.ObjectID, quantity float64) {
+	owner, ok := resolve(c, false)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	cart, err := loadCart(ctx, db, owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if !cartHasProduct(cart, productID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not in cart"})
+		return
+	}
+
+	if quantity > 0 {
+		product, err := loadCartProduct(ctx, db, productID)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if err := checkCartQuantity(product, quantity); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	cart, err = saveCartItems(ctx, db, owner, setCartItemQuantity(cart.Items, productID, quantity))
+	if err != nil {
+		log.Println("[CART] [ERROR] update cart line failed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	respondCart(c, ctx, db, owner, cart)
+}
+
+func cartHasProduct(cart models.Cart, productID primitive.ObjectID) bool {
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// cartLineQuantity returns the quantity of productID in cart, or zero.
+func cartLineQuantity(cart models.Cart, productID primitive.ObjectID) float64 {
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// loadCartProduct returns an active product that can be added to a cart.
+func loadCartProduct(ctx context.Context, db *mongo.Database, productID primitive.ObjectID) (models.Product, error) {
+	var raw bson.M
+	err := db.Collection("products").FindOne(ctx, bson.M{
+		"_id":       productID,
+		"isActive":  bson.M{"$ne": false},
+		"isDeleted": bson.M{"$ne": true},
+	}).Decode(&raw)
+	if err != nil {
+		return models.Product{}, err
+	}
+	return normalizeProductDocument(raw)
+}
+
+// checkCartQuantity validates a cart line quantity against the product's
+// unit rules; piece lines are also capped at maxCartLineQuantity.
+func checkCartQuantity(product models.Product, quantity float64) error {
+	if !product.IsWeighed() && quantity > maxCartLineQuantity {
+		return fmt.Errorf("quantity must be at most %d", maxCartLineQuantity)
+	}
+	return product.CheckQuantity(quantity)
+}
+
+func respondCart(c *gin.Context, ctx context.Context, db *mongo.Database, owner cartOwner, cart models.Cart) {
+	view, err := buildCartView(ctx, db, cart, nil)
+	if err != nil {
+		log.Println("[CART] [ERROR] price cart failed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	view.CartToken = owner.token
+	c.JSON(http.StatusOK, view)
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// maxCartLineQuantity caps a single piece cart line to keep typos from turning
+// into absurd baskets. Weighed lines are limited by the product's maximum.
+const maxCartLineQuantity = 999
+
+// Cart line statuses reported by the re-priced cart view.
+const (
+	cartLineOK                = "ok"
+	cartLineNotFound          = "not_found"
+	cartLineDeleted           = "deleted"
+	cartLineInactive          = "inactive"
+	cartLineOutOfStock        = "out_of_stock"
+	cartLineInsufficientStock = "insufficient_stock"
+	cartLineInvalidQuantity   = "invalid_quantity"
+	cartLineVariantRequired   = "variant_required"
+)
+
+type cartLineView struct {
+	ProductID     string  `json:"productId"`
+	Name          string  `json:"name"`
+	VariantName   string  `json:"variantName,omitempty"`
+	ImageURL      string  `json:"imageUrl"`
+	Price         float64 `json:"price"`
+	OriginalPrice float64 `json:"originalPrice"`
+	IsCampaign    bool    `json:"isCampaign"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit,omitempty"`
+	LineTotal     float64 `json:"lineTotal"`
+	Available     float64 `json:"available"`
+	Status        string  `json:"status"`
+	Purchasable   bool    `json:"purchasable"`
+}
+
+type cartView struct {
+	Items     []cartLineView `json:"items"`
+	Subtotal  float64        `json:"subtotal"`
+	ItemCount int            `json:"itemCount"`
+	HasIssues bool           `json:"hasIssues"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	CartToken string         `json:"cartToken,omitempty"`
+}
+
+// cartOwner identifies whose cart a request works on. A nil filter means a
+// guest without a cart yet; guest owners also carry the refreshed token and
+// the expiry written on save.
+type cartOwner struct {
+	filter    bson.M
+	token     string
+	expiresAt *time.Time
+}
+
+func userCartOwnerFor(userID primitive.ObjectID) cartOwner {
+	return cartOwner{filter: bson.M{"userId": userID}}
+}
+
+func guestCartOwnerFor(guestID string, token string, expiresAt time.Time) cartOwner {
+	return cartOwner{filter: bson.M{"guestId": guestID}, token: token, expiresAt: &expiresAt}
+}
+
+// loadCart returns the cart matching owner, or an empty cart when there is
+// none yet.
+func loadCart(ctx context.Context, db *mongo.Database, owner cartOwner) (models.Cart, error) {
+	if owner.filter == nil {
+		return models.Cart{Items: []models.CartItem{}}, nil
+	}
+
+	var cart models.Cart
+	err := db.Collection("carts").FindOne(ctx, owner.filter).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return models.Cart{Items: []models.CartItem{}}, nil
+	}
+	if err != nil {
+		return models.Cart{}, err
+	}
+	if cart.Items == nil {
+		cart.Items = []models.CartItem{}
+	}
+	return cart, nil
+}
+
+// saveCartItems replaces the items of the cart matching owner, creating the
+// cart on first write.
+func saveCartItems(ctx context.Context, db *mongo.Database, owner cartOwner, items []models.CartItem) (models.Cart, error) {
+	now := time.Now()
+	setOnInsert := bson.M{"createdAt": now}
+	for k, v := range owner.filter {
+		setOnInsert[k] = v
+	}
+
+	set := bson.M{"items": items, "updatedAt": now}
+	if owner.expiresAt != nil {
+		set["expiresAt"] = *owner.expiresAt
+	}
+
+	var cart models.Cart
+	err := db.Collection("carts").FindOneAndUpdate(
+		ctx,
+		owner.filter,
+		bson.M{
+			"$set":         set,
+			"$setOnInsert": setOnInsert,
+		},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	).Decode(&cart)
+	return cart, err
+}
+
+// setCartItemQuantity returns items with productID set to quantity; zero
+// removes the line. Callers validate quantity; see checkCartQuantity.
+func setCartItemQuantity(items []models.CartItem, productID primitive.ObjectID, quantity float64) []models.CartItem {
+	out := make([]models.CartItem, 0, len(items)+1)
+	found := false
+	for _, item := range items {
+		if item.ProductID != productID {
+			out = append(out, item)
+			continue
+		}
+		found = true
+		item.Quantity = quantity
+		if item.Quantity > 0 {
+			out = append(out, item)
+		}
+	}
+	if !found && quantity > 0 {
+		out = append(out, models.CartItem{ProductID: productID, Quantity: quantity, AddedAt: time.Now()})
+	}
+	return out
+}
+
+// mergeCartItems folds guest lines into the user's lines. When both carts hold
+// the same product the larger quantity wins, so adding the same item on two
+// devices does not double it and both quantities were already validated.
+func mergeCartItems(userItems, guestItems []models.CartItem) []models.CartItem {
+	out := make([]models.CartItem, 0, len(userItems)+len(guestItems))
+	index := make(map[primitive.ObjectID]int, len(userItems))
+	for _, item := range userItems {
+		index[item.ProductID] = len(out)
+		out = append(out, item)
+	}
+	for _, item := range guestItems {
+		if i, ok := index[item.ProductID]; ok {
+			if item.Quantity > out[i].Quantity {
+				out[i].Quantity = item.Quantity
+			}
+			continue
+		}
+		index[item.ProductID] = len(out)
+		out = append(out, item)
+	}
+	return out
+}
+
+// mergeGuestCart moves the guest cart into the user's cart in one transaction
+// and deletes the guest cart. It reports whether any guest lines existed.
+func mergeGuestCart(ctx context.Context, db *mongo.Database, guestID string, userID primitive.ObjectID) (bool, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return false, err
+	}
+	defer session.EndSession(ctx)
+
+	guestOwner := cartOwner{filter: bson.M{"guestId": guestID}}
+	userOwner := userCartOwnerFor(userID)
+
+	merged, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		guest, err := loadCart(sessCtx, db, guestOwner)
+		if err != nil {
+			return false, err
+		}
+		if len(guest.Items) == 0 {
+			return false, nil
+		}
+
+		current, err := loadCart(sessCtx, db, userOwner)
+		if err != nil {
+			return false, err
+		}
+		if _, err := saveCartItems(sessCtx, db, userOwner, mergeCartItems(current.Items, guest.Items)); err != nil {
+			return false, err
+		}
+		if _, err := db.Collection("carts").DeleteOne(sessCtx, guestOwner.filter); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return merged.(bool), nil
+}
+
+// buildCartView re-prices cart from the current product documents and flags
+// lines that can no longer be bought. held is what the caller's own stock
+// reservation holds per product; it counts as available (may be nil).
+func buildCartView(ctx context.Context, db *mongo.Database, cart models.Cart, held map[primitive.ObjectID]float64) (cartView, error) {
+	view := cartView{Items: make([]cartLineView, 0, len(cart.Items)), UpdatedAt: cart.UpdatedAt}
+	if len(cart.Items) == 0 {
+		return view, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.ProductID)
+	}
+
+	cursor, err := db.Collection("products").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return cartView{}, err
+	}
+	defer cursor.Close(ctx)
+
+	list, err := decodeProducts(ctx, cursor)
+	if err != nil {
+		return cartView{}, err
+	}
+	if err := applyActiveCampaigns(ctx, db, list); err != nil {
+		return cartView{}, err
+	}
+	products := make(map[primitive.ObjectID]models.Product, len(list))
+	for _, p := range list {
+		products[p.ID] = p
+	}
+
+	var subtotal float64
+	for _, item := range cart.Items {
+		line := cartLineView{
+			ProductID: item.ProductID.Hex(),
+			Quantity:  item.Quantity,
+			Status:    cartLineOK,
+		}
+
+		product, ok := products[item.ProductID]
+		available := product.AvailableStock() + held[item.ProductID]
+		switch {
+		case !ok:
+			line.Status = cartLineNotFound
+		case product.IsDeleted:
+			line.Status = cartLineDeleted
+		case !product.IsActive:
+			line.Status = cartLineInactive
+		case product.HasVariants:
+			line.Status = cartLineVariantRequired
+		case available <= 0:
+			line.Status = cartLineOutOfStock
+		case available < item.Quantity:
+			line.Status = cartLineInsufficientStock
+		case product.CheckQuantity(item.Quantity) != nil:
+			line.Status = cartLineInvalidQuantity
+		}
+
+		if ok {
+			line.Name = product.Name
+			line.VariantName = product.VariantName
+			line.ImageURL = product.ImageURL
+			line.Unit = product.UnitOrDefault()
+			line.Price = product.Price
+			line.OriginalPrice = product.OriginalPrice
+			line.IsCampaign = product.IsCampaign
+			line.Available = available
+			line.LineTotal = roundPrice(product.Price * item.Quantity)
+		}
+
+		line.Purchasable = line.Status == cartLineOK
+		if line.Purchasable {
+			subtotal += line.LineTotal
+			view.ItemCount += cartItemCount(product, item.Quantity)
+		} else {
+			view.HasIssues = true
+		}
+		view.Items = append(view.Items, line)
+	}
+
+	view.Subtotal = roundPrice(subtotal)
+	return view, nil
+}
+
+// cartItemCount is what a line adds to the cart's item count: its pieces, or
+// one for a weighed line.
+func cartItemCount(product models.Product, quantity float64) int {
+	if product.IsWeighed() {
+		return 1
+	}
+	return int(quantity)
+}
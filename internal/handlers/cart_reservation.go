@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+)
+
+type reserveItemRequest struct {
+	ProductID string  `json:"productId" binding:"required"`
+	Quantity  float64 `json:"quantity" binding:"required"`
+}
+
+type reserveRequest struct {
+	Items []reserveItemRequest `json:"items" binding:"required"`
+}
+
+/*
+POST /cart/reserve
+- Ürünleri ödeme süresince (ttl) rezerve eder
+- Token varsa rezervasyon kullanıcıya bağlanır, önceki rezervasyonu bırakılır
+- Misafirler X-Cart-Token göndermek zorundadır; sepet başına tek aktif rezervasyon tutulur
+- Miktarlar ürünün adım ve min/max sınırlarına göre doğrulanır
+- Dönen reservationId, POST /orders isteğinde gönderilir
+*/
+func ReserveStock(db *mongo.Database, jwtSecret string, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /cart/reserve"
+		defer handlePanic(c, route)
+
+		var req reserveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid request body")
+			return
+		}
+		if len(req.Items) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "at least one item is required")
+			return
+		}
+
+		userID, err := userIDFromHeader(c.GetHeader("Authorization"), jwtSecret)
+		if err != nil {
+			log.Println("[RESERVE] [ERROR] token validation failed:", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		guestID, ok := reservationGuest(c, route, userID, jwtSecret)
+		if !ok {
+			return
+		}
+
+		items := make([]models.StockReservationItem, 0, len(req.Items))
+		for _, item := range req.Items {
+			productID, err := primitive.ObjectIDFromHex(item.ProductID)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "invalid productId")
+				return
+			}
+			if item.Quantity <= 0 {
+				respondWithError(c, http.StatusBadRequest, route, "quantity must be greater than zero")
+				return
+			}
+			items = append(items, models.StockReservationItem{ProductID: productID, Quantity: item.Quantity})
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		items = inventory.MergeReservationItems(items)
+		if !checkReservationQuantities(c, ctx, db, route, items) {
+			return
+		}
+
+		reservation, err := inventory.Reserve(ctx, db, userID, guestID, items, ttl)
+		if err != nil {
+			respondInventoryError(c, route, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"reservationId": reservation.ID.Hex(),
+			"items":         reservation.Items,
+			"expiresAt":     reservation.ExpiresAt,
+		})
+	}
+}
+
+/*
+DELETE /cart/reserve/:id
+- Rezervasyonu süresi dolmadan bırakır
+*/
+func ReleaseReservation(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /cart/reserve/:id"
+		defer handlePanic(c, route)
+
+		reservationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		userID, err := userIDFromHeader(c.GetHeader("Authorization"), jwtSecret)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		guestID, ok := reservationGuest(c, route, userID, jwtSecret)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := inventory.Release(ctx, db, reservationID, userID, guestID); err != nil {
+			respondInventoryError(c, route, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "reservation released"})
+	}
+}
+
+// reservationGuest returns the guest id behind X-Cart-Token for requests
+// without a user token. Guests must send a valid cart token so their holds
+// can be limited to one per cart.
+func reservationGuest(c *gin.Context, route string, userID *primitive.ObjectID, secret string) (string, bool) {
+	if userID != nil {
+		return "", true
+	}
+	raw := strings.TrimSpace(c.GetHeader(cartTokenHeader))
+	if raw == "" {
+		respondWithError(c, http.StatusUnauthorized, route, "cart token required")
+		return "", false
+	}
+	guestID, err := parseCartToken(raw, secret)
+	if err != nil {
+		log.Println("[RESERVE] [ERROR] cart token validation failed:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid cart token"})
+		return "", false
+	}
+	return guestID, true
+}
+
+// checkReservationQuantities validates each merged quantity against its
+// product's step and limits. Missing products are left to inventory.Reserve.
+func checkReservationQuantities(c *gin.Context, ctx context.Context, db *mongo.Database, route string, items []models.StockReservationItem) bool {
+	ids := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	cursor, err := db.Collection("products").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, route, "db error")
+		return false
+	}
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		respondWithError(c, http.StatusInternalServerError, route, "db error")
+		return false
+	}
+	byID := make(map[primitive.ObjectID]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	for _, item := range items {
+		product, ok := byID[item.ProductID]
+		if !ok {
+			continue
+		}
+		if err := product.CheckQuantity(item.Quantity); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     err.Error(),
+				"productId": item.ProductID.Hex(),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// respondInventoryError maps inventory package errors to HTTP responses.
+func respondInventoryError(c *gin.Context, route string, err error) {
+	var stockErr inventory.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Stok yetersiz",
+			"productId": stockErr.ProductID.Hex(),
+			"available": stockErr.Available,
+			"requested": stockErr.Requested,
+		})
+		return
+	}
+	var notFoundErr inventory.ProductNotFoundError
+	if errors.As(err, &notFoundErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Ürün bulunamadı",
+			"productId": notFoundErr.ProductID.Hex(),
+		})
+		return
+	}
+	if errors.Is(err, inventory.ErrReservationNotFound) {
+		respondWithError(c, http.StatusConflict, route, "Rezervasyon bulunamadı veya süresi doldu")
+		return
+	}
+	if errors.Is(err, inventory.ErrReservationOwner) {
+		respondWithError(c, http.StatusForbidden, route, "forbidden")
+		return
+	}
+	respondWithError(c, http.StatusInternalServerError, route, "db error")
+}
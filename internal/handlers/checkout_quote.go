@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+)
+
+// checkoutQuoteRequest has the createOrderRequest shape; only items are
+// required so a quote can be asked before the checkout form is filled in.
+type checkoutQuoteRequest struct {
+	Items         []createOrderItemRequest         `json:"items" binding:"required"`
+	TotalPrice    *float64                         `json:"totalPrice"`
+	Customer      *createOrderCustomerRequest      `json:"customer"`
+	PaymentMethod *createOrderPaymentMethodRequest `json:"paymentMethod"`
+	ReservationID string                           `json:"reservationId"`
+	CouponCode    string                           `json:"couponCode"`
+	AddressID     string                           `json:"addressId"`
+}
+
+type quoteLineView struct {
+	ProductID     string   `json:"productId"`
+	Name          string   `json:"name"`
+	VariantName   string   `json:"variantName,omitempty"`
+	Price         float64  `json:"price"`
+	OriginalPrice float64  `json:"originalPrice,omitempty"`
+	IsCampaign    bool     `json:"isCampaign"`
+	Quantity      float64  `json:"quantity"`
+	Unit          string   `json:"unit,omitempty"`
+	LineTotal     float64  `json:"lineTotal"`
+	Discount      float64  `json:"discount"`
+	Total         float64  `json:"total"`
+	Promotions    []string `json:"promotions"`
+	Available     float64  `json:"available"`
+	InStock       bool     `json:"inStock"`
+}
+
+type quoteCouponError struct {
+	Code      string  `json:"code"`
+	Reason    string  `json:"reason"`
+	MinBasket float64 `json:"minBasket,omitempty"`
+}
+
+type quoteView struct {
+	Items       []quoteLineView           `json:"items"`
+	Subtotal    float64                   `json:"subtotal"`
+	Promotions  []models.AppliedPromotion `json:"promotions"`
+	Coupon      *models.OrderCoupon       `json:"coupon,omitempty"`
+	CouponError *quoteCouponError         `json:"couponError,omitempty"`
+	Discount    float64                   `json:"discount"`
+	DeliveryFee float64                   `json:"deliveryFee"`
+	TotalPrice  float64                   `json:"totalPrice"`
+	// DeliveryZone is the zone of the customer's coordinates, if given.
+	DeliveryZone *models.OrderDeliveryZone `json:"deliveryZone,omitempty"`
+	// LocationRequired is set when delivery zones are defined but no
+	// coordinates were given: DeliveryFee is the general fee, and the order
+	// itself will need a location.
+	LocationRequired bool   `json:"locationRequired,omitempty"`
+	Warning          string `json:"warning,omitempty"`
+	// MinOrderAmount, FreeDeliveryThreshold and AmountToFreeDelivery let the
+	// app show how far the basket is from each delivery rule.
+	MinOrderAmount        float64 `json:"minOrderAmount"`
+	BelowMinimum          bool    `json:"belowMinimum"`
+	FreeDeliveryThreshold float64 `json:"freeDeliveryThreshold"`
+	AmountToFreeDelivery  float64 `json:"amountToFreeDelivery"`
+	// CanOrder is false when a line is short on stock or the basket is below
+	// the minimum order amount.
+	CanOrder bool `json:"canOrder"`
+	// TotalMatches compares a submitted totalPrice with TotalPrice.
+	TotalMatches *bool `json:"totalMatches,omitempty"`
+}
+
+/*
+POST /checkout/quote
+- POST /orders ile aynı gövdeyi kabul eder; sadece items zorunlu
+- Sipariş ile aynı fiyatlama kodunu çalıştırır, hiçbir şey yazmaz
+- Satır bazında fiyat, indirim, promosyon ve stok durumu döner
+- Geçersiz kupon isteği bozmaz; kuponsuz fiyat + couponError döner
+- customer konumu verilirse teslimat bölgesinin ücreti uygulanır; bölge dışı 400 döner
+- Konum yokken bölge tanımlıysa genel ücret + locationRequired uyarısı döner
+*/
+func CheckoutQuote(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /checkout/quote"
+		defer handlePanic(c, route)
+
+		var req checkoutQuoteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid request body")
+			return
+		}
+		if len(req.Items) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "at least one item is required")
+			return
+		}
+		if req.PaymentMethod != nil && req.PaymentMethod.ID != "cash" && req.PaymentMethod.ID != "card" {
+			respondWithError(c, http.StatusBadRequest, route, "invalid payment method")
+			return
+		}
+
+		userID, err := userIDFromHeader(c.GetHeader("Authorization"), jwtSecret)
+		if err != nil {
+			log.Println("[QUOTE] [ERROR] token validation failed:", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		items, err := buildOrderItems(req.Items)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		order := models.Order{UserID: userID, Items: items}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		// The address only matters for its delivery zone, so a partial
+		// customer is accepted here.
+		if req.AddressID != "" {
+			order.Customer, err = resolveOrderCustomer(ctx, db, userID, req.AddressID, req.Customer)
+			if err != nil {
+				respondOrderAddressError(c, route, err)
+				return
+			}
+		} else if req.Customer != nil {
+			if err := validateCoordinates(req.Customer.Latitude, req.Customer.Longitude); err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			order.Customer = orderCustomerFromRequest(*req.Customer)
+		}
+
+		held := map[primitive.ObjectID]float64{}
+		if req.ReservationID != "" {
+			reservationID, err := primitive.ObjectIDFromHex(req.ReservationID)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "invalid reservationId")
+				return
+			}
+			guestID, ok := reservationGuest(c, route, userID, jwtSecret)
+			if !ok {
+				return
+			}
+			if held, err = inventory.Held(ctx, db, reservationID, userID, guestID); err != nil {
+				respondOrderError(c, route, err)
+				return
+			}
+		}
+
+		priced, couponErr, err := quoteOrder(ctx, db, &order, req.CouponCode)
+		if err != nil {
+			respondOrderError(c, route, err)
+			return
+		}
+
+		view := buildQuoteView(order, priced, held)
+		err = requireDeliveryLocation(ctx, db, order.Customer.Latitude, order.Customer.Longitude)
+		if errors.As(err, &deliveryLocationRequiredError{}) {
+			view.LocationRequired = true
+			view.Warning = "Teslimat ücreti konuma göre değişebilir; sipariş için adresin konumu gerekli"
+		} else if err != nil {
+			respondOrderError(c, route, err)
+			return
+		}
+		if couponErr != nil {
+			view.CouponError = &quoteCouponError{
+				Code:      couponErr.Code,
+				Reason:    couponErr.Reason,
+				MinBasket: couponErr.MinBasket,
+			}
+		}
+		if req.TotalPrice != nil {
+			matches := verifyClientTotal(req.TotalPrice, order) == nil
+			view.TotalMatches = &matches
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+// quoteOrder prices order like placeOrder does. A coupon that cannot be used
+// is reported instead of failing the quote, and the order is priced without
+// it.
+func quoteOrder(ctx context.Context, db *mongo.Database, order *models.Order, couponCode string) (pricedOrder, *couponError, error) {
+	priced, err := priceOrder(ctx, db, order, couponCode)
+	var couponErr couponError
+	if errors.As(err, &couponErr) {
+		priced, err = priceOrder(ctx, db, order, "")
+		if err != nil {
+			return pricedOrder{}, nil, err
+		}
+		return priced, &couponErr, nil
+	}
+	return priced, nil, err
+}
+
+func buildQuoteView(order models.Order, priced pricedOrder, held map[primitive.ObjectID]float64) quoteView {
+	names := map[primitive.ObjectID][]string{}
+	for _, promotion := range order.Promotions {
+		for _, line := range promotion.Lines {
+			names[line.ProductID] = append(names[line.ProductID], promotion.Name)
+		}
+	}
+
+	view := quoteView{
+		Items:       make([]quoteLineView, 0, len(order.Items)),
+		Promotions:  order.Promotions,
+		Coupon:      order.Coupon,
+		Discount:    order.Discount,
+		DeliveryFee: order.DeliveryFee,
+		TotalPrice:  order.TotalPrice,
+		CanOrder:    true,
+
+		DeliveryZone: order.DeliveryZone,
+
+		MinOrderAmount:        priced.Delivery.MinOrderAmount,
+		FreeDeliveryThreshold: priced.Delivery.FreeDeliveryThreshold,
+	}
+
+	goods := roundPrice(order.Subtotal - order.Discount)
+	if checkMinimumOrder(order, priced.Delivery) != nil {
+		view.BelowMinimum = true
+		view.CanOrder = false
+	}
+	if order.DeliveryFee > 0 && priced.Delivery.FreeDeliveryThreshold > goods {
+		view.AmountToFreeDelivery = roundPrice(priced.Delivery.FreeDeliveryThreshold - goods)
+	}
+	if view.Promotions == nil {
+		view.Promotions = []models.AppliedPromotion{}
+	}
+
+	for _, item := range order.Items {
+		lineTotal := roundPrice(item.Price * item.Quantity)
+
+		promotionNames := names[item.ProductID]
+		if promotionNames == nil {
+			promotionNames = []string{}
+		}
+
+		available := priced.Products[item.ProductID].AvailableStock() + held[item.ProductID]
+		inStock := available >= item.Quantity
+		if !inStock {
+			view.CanOrder = false
+		}
+
+		view.Items = append(view.Items, quoteLineView{
+			ProductID:     item.ProductID.Hex(),
+			Name:          item.Name,
+			VariantName:   item.VariantName,
+			Price:         item.Price,
+			OriginalPrice: item.OriginalPrice,
+			IsCampaign:    item.IsCampaign,
+			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			LineTotal:     lineTotal,
+			Discount:      item.Discount,
+			Total:         roundPrice(lineTotal - item.Discount),
+			Promotions:    promotionNames,
+			Available:     available,
+			InStock:       inStock,
+		})
+	}
+	view.Subtotal = order.Subtotal
+	return view
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/models"
+)
+
+// validateCoordinates accepts either both coordinates or neither.
+func validateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if *latitude < -90 || *latitude > 90 {
+		return errors.New("invalid latitude")
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return errors.New("invalid longitude")
+	}
+	return nil
+}
+
+// locateDeliveryZone finds the zone serving the coordinates. served is false
+// only when zones are defined, the location is known and none covers it;
+// addresses without coordinates and stores without zones are always served.
+func locateDeliveryZone(ctx context.Context, db *mongo.Database, latitude, longitude *float64) (zone *models.DeliveryZone, served bool, err error) {
+	if latitude == nil || longitude == nil {
+		return nil, true, nil
+	}
+
+	zone, err = delivery.FindZone(ctx, db, *latitude, *longitude)
+	if err != nil {
+		return nil, false, err
+	}
+	if zone != nil {
+		return zone, true, nil
+	}
+
+	hasZones, err := delivery.HasZones(ctx, db)
+	if err != nil {
+		return nil, false, err
+	}
+	return nil, !hasZones, nil
+}
+
+// requireDeliveryLocation rejects an order without coordinates while delivery
+// zones are defined: its zone, and so whether and for how much it can be
+// delivered, cannot be told. Saved addresses may still lack coordinates.
+func requireDeliveryLocation(ctx context.Context, db *mongo.Database, latitude, longitude *float64) error {
+	if latitude != nil && longitude != nil {
+		return nil
+	}
+	hasZones, err := delivery.HasZones(ctx, db)
+	if err != nil {
+		return err
+	}
+	if hasZones {
+		return deliveryLocationRequiredError{}
+	}
+	return nil
+}
+
+// addressZoneResponse adds the delivery zone verdict to an address response.
+// Addresses outside every zone are still saved, with a warning.
+func addressZoneResponse(ctx context.Context, db *mongo.Database, address models.Address) gin.H {
+	resp := gin.H{"address": address}
+
+	zone, served, err := locateDeliveryZone(ctx, db, address.Latitude, address.Longitude)
+	if err != nil {
+		log.Println("[ADDRESS] [ERROR] delivery zone lookup failed:", err)
+		return resp
+	}
+
+	resp["deliverable"] = served
+	if zone != nil {
+		resp["deliveryZone"] = models.OrderDeliveryZone{ZoneID: zone.ID, Name: zone.Name}
+	}
+	if !served {
+		resp["warning"] = "Bu adres teslimat bölgelerimizin dışında"
+	}
+	return resp
+}
+
+type outsideDeliveryZoneError struct{}
+
+func (e outsideDeliveryZoneError) Error() string {
+	return "address outside delivery zones"
+}
+
+type deliveryLocationRequiredError struct{}
+
+func (e deliveryLocationRequiredError) Error() string {
+	return "delivery location required"
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// cartTokenHeader carries the signed guest cart token on /cart requests.
+const cartTokenHeader = "X-Cart-Token"
+
+const cartTokenType = "cart"
+
+func issueCartToken(guestID, secret string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"typ":     cartTokenType,
+		"guestId": guestID,
+		"exp":     expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// parseCartToken returns the guest id of a valid cart token. Cart tokens carry
+// no userId claim, so they are never accepted as user tokens.
+func parseCartToken(raw, secret string) (string, error) {
+	token, err := jwt.Parse(strings.TrimSpace(raw), func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return "", errors.New("invalid cart token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != cartTokenType {
+		return "", errors.New("invalid cart token claims")
+	}
+
+	guestID, ok := claims["guestId"].(string)
+	if !ok || strings.TrimSpace(guestID) == "" {
+		return "", errors.New("guestId claim missing")
+	}
+	return guestID, nil
+}
+
+// guestCartOwner resolves the guest cart from the X-Cart-Token header. Every
+// resolved request gets a fresh token so an active cart never expires.
+func guestCartOwner(secret string, ttl time.Duration) cartOwnerResolver {
+	return func(c *gin.Context, create bool) (cartOwner, bool) {
+		raw := strings.TrimSpace(c.GetHeader(cartTokenHeader))
+
+		var guestID string
+		switch {
+		case raw != "":
+			id, err := parseCartToken(raw, secret)
+			if err != nil {
+				log.Println("[CART] [ERROR] cart token validation failed:", err)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid cart token"})
+				return cartOwner{}, false
+			}
+			guestID = id
+		case create:
+			guestID = primitive.NewObjectID().Hex()
+		default:
+			return cartOwner{}, true
+		}
+
+		expiresAt := time.Now().Add(ttl)
+		token, err := issueCartToken(guestID, secret, expiresAt)
+		if err != nil {
+			log.Println("[CART] [ERROR] cart token generation failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+			return cartOwner{}, false
+		}
+		return guestCartOwnerFor(guestID, token, expiresAt), true
+	}
+}
+
+/*
+GET /cart
+- Misafir sepeti, X-Cart-Token header ile bulunur
+- Token yoksa boş sepet döner
+*/
+func GetGuestCart(db *mongo.Database, secret string, ttl time.Duration) gin.HandlerFunc {
+	return getCartHandler(db, guestCartOwner(secret, ttl))
+}
+
+/*
+POST /cart/items
+- Token yoksa yeni misafir sepeti açılır, cartToken yanıtta döner
+*/
+func AddGuestCartItem(db *mongo.Database, secret string, ttl time.Duration) gin.HandlerFunc {
+	return addCartItemHandler(db, guestCartOwner(secret, ttl))
+}
+
+/*
+PUT /cart/items/:productId
+*/
+func SetGuestCartItemQuantity(db *mongo.Database, secret string, ttl time.Duration) gin.HandlerFunc {
+	return setCartItemQuantityHandler(db, guestCartOwner(secret, ttl))
+}
+
+/*
+DELETE /cart/items/:productId
+*/
+func RemoveGuestCartItem(db *mongo.Database, secret string, ttl time.Duration) gin.HandlerFunc {
+	return removeCartItemHandler(db, guestCartOwner(secret, ttl))
+}
+
+/*
+DELETE /cart
+*/
+func ClearGuestCart(db *mongo.Database, secret string, ttl time.Duration) gin.HandlerFunc {
+	return clearCartHandler(db, guestCartOwner(secret, ttl))
+}
+
+// mergeCartOnAuth merges the guest cart behind cartToken into the user's cart.
+// Failures are logged and never block the login itself.
+func mergeCartOnAuth(c *gin.Context, db *mongo.Database, cartToken, secret string, userID primitive.ObjectID) bool {
+	guestID, err := parseCartToken(cartToken, secret)
+	if err != nil {
+		log.Println("[CART] [ERROR] merge skipped, invalid cart token:", err)
+		return false
+	}
+
+	merged, err := mergeGuestCart(c.Request.Context(), db, guestID, userID)
+	if err != nil {
+		log.Println("[CART] [ERROR] guest cart merge failed:", err)
+		return false
+	}
+	if merged {
+		log.Println("[CART] [INFO] guest cart merged into user:", userID.Hex())
+	}
+	return merged
+}
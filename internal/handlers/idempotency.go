@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	maxIdempotencyKey = 255
+
+	// idempotencyLockTimeout is how long a "processing" record blocks retries
+	// before it is considered abandoned (e.g. the server died mid-request).
+	idempotencyLockTimeout = time.Minute
+)
+
+// idempotencyWriter tees the response body so it can be stored after the
+// handler finishes.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes the wrapped route honour the Idempotency-Key header. The
+// first response for a key and caller (see idempotencyCaller) is
+// stored in idempotency_keys; replays with the same payload get the stored
+// response back without running the handler again. Server errors are not
+// stored so the client can retry them.
+func Idempotency(db *mongo.Database, jwtSecret string, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "IDEMPOTENCY"
+
+		key := strings.TrimSpace(c.GetHeader(idempotencyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			respondWithError(c, http.StatusBadRequest, route, "idempotency key too long")
+			return
+		}
+
+		userID, err := userIDFromHeader(c.GetHeader("Authorization"), jwtSecret)
+		if err != nil {
+			// Let the handler reject the token.
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+		fingerprint := idempotencyCaller(c, userID, jwtSecret, requestHash)
+
+		recordID := hashToken(c.Request.Method + " " + c.FullPath() + "|" + fingerprint + "|" + key)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		collection := db.Collection("idempotency_keys")
+		now := time.Now()
+		record := models.IdempotencyRecord{
+			ID:          recordID,
+			State:       models.IdempotencyProcessing,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		if _, err := collection.InsertOne(ctx, record); err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+
+			var existing models.IdempotencyRecord
+			if err := collection.FindOne(ctx, bson.M{"_id": recordID}).Decode(&existing); err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+
+			if existing.RequestHash != requestHash {
+				respondWithError(c, http.StatusUnprocessableEntity, route, "idempotency key reused with a different request")
+				return
+			}
+
+			if existing.State == models.IdempotencyCompleted {
+				log.Printf("[%s] replaying stored response for %s", route, c.FullPath())
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.ResponseStatus, "application/json; charset=utf-8", existing.ResponseBody)
+				c.Abort()
+				return
+			}
+
+			// Take over an abandoned lock; otherwise the first request is
+			// still running.
+			res, err := collection.UpdateOne(
+				ctx,
+				bson.M{
+					"_id":       recordID,
+					"state":     models.IdempotencyProcessing,
+					"createdAt": bson.M{"$lt": now.Add(-idempotencyLockTimeout)},
+				},
+				bson.M{"$set": bson.M{"createdAt": now, "expiresAt": now.Add(ttl)}},
+			)
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+			if res.MatchedCount == 0 {
+				respondWithError(c, http.StatusConflict, route, "request with this idempotency key is in progress")
+				return
+			}
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// The request context may already be done; store the result anyway.
+		storeCtx, storeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer storeCancel()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			if _, err := collection.DeleteOne(storeCtx, bson.M{"_id": recordID}); err != nil {
+				log.Printf("[%s] releasing key failed: %v", route, err)
+			}
+			return
+		}
+
+		_, err = collection.UpdateOne(storeCtx, bson.M{"_id": recordID}, bson.M{"$set": bson.M{
+			"state":          models.IdempotencyCompleted,
+			"responseStatus": status,
+			"responseBody":   writer.body.Bytes(),
+		}})
+		if err != nil {
+			log.Printf("[%s] storing response failed: %v", route, err)
+		}
+	}
+}
+
+// idempotencyCaller scopes keys to the user, or to the guest cart behind
+// X-Cart-Token. Other guests are scoped by the request body: guests behind
+// one address (e.g. a carrier NAT) can never replay each other's responses,
+// and a retry only matches when it repeats the same request.
+func idempotencyCaller(c *gin.Context, userID *primitive.ObjectID, secret, requestHash string) string {
+	if userID != nil {
+		return "user:" + userID.Hex()
+	}
+	if raw := strings.TrimSpace(c.GetHeader(cartTokenHeader)); raw != "" {
+		if guestID, err := parseCartToken(raw, secret); err == nil {
+			return "guest:" + guestID
+		}
+	}
+	return "request:" + requestHash
+}
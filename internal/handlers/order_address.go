@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// orderAddressError is a client mistake in choosing the order address.
+type orderAddressError struct {
+	Message string
+}
+
+func (e orderAddressError) Error() string {
+	return e.Message
+}
+
+// resolveOrderCustomer returns the delivery address snapshot for an order:
+// the saved address addressID of the logged-in user, or the free-text
+// customer of a guest or user.
+func resolveOrderCustomer(ctx context.Context, db *mongo.Database, userID *primitive.ObjectID, addressID string, customer *createOrderCustomerRequest) (models.OrderCustomer, error) {
+	addressID = strings.TrimSpace(addressID)
+	switch {
+	case addressID != "" && customer != nil:
+		return models.OrderCustomer{}, orderAddressError{Message: "send either customer or addressId"}
+	case addressID == "" && customer == nil:
+		return models.OrderCustomer{}, orderAddressError{Message: "customer or addressId is required"}
+	case addressID == "":
+		if strings.TrimSpace(customer.Title) == "" || strings.TrimSpace(customer.Detail) == "" {
+			return models.OrderCustomer{}, orderAddressError{Message: "customer title and detail are required"}
+		}
+		if err := validateCoordinates(customer.Latitude, customer.Longitude); err != nil {
+			return models.OrderCustomer{}, orderAddressError{Message: err.Error()}
+		}
+		return orderCustomerFromRequest(*customer), nil
+	case userID == nil:
+		return models.OrderCustomer{}, orderAddressError{Message: "addressId requires login"}
+	}
+
+	var user models.User
+	err := db.Collection("users").FindOne(
+		ctx,
+		bson.M{"_id": *userID, "addresses.id": addressID},
+		options.FindOne().SetProjection(bson.M{"addresses.$": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && len(user.Addresses) == 0) {
+		return models.OrderCustomer{}, orderAddressError{Message: "address not found"}
+	}
+	if err != nil {
+		return models.OrderCustomer{}, err
+	}
+
+	address := user.Addresses[0]
+	return models.OrderCustomer{
+		AddressID:     address.ID,
+		Title:         address.Title,
+		Detail:        address.Detail,
+		Note:          address.Note,
+		City:          address.City,
+		District:      address.District,
+		Neighbourhood: address.Neighbourhood,
+		Latitude:      address.Latitude,
+		Longitude:     address.Longitude,
+	}, nil
+}
+
+func orderCustomerFromRequest(req createOrderCustomerRequest) models.OrderCustomer {
+	return models.OrderCustomer{
+		Title:         strings.TrimSpace(req.Title),
+		Detail:        strings.TrimSpace(req.Detail),
+		Note:          strings.TrimSpace(req.Note),
+		City:          strings.TrimSpace(req.City),
+		District:      strings.TrimSpace(req.District),
+		Neighbourhood: strings.TrimSpace(req.Neighbourhood),
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
+	}
+}
+
+// respondOrderAddressError maps resolveOrderCustomer errors to responses.
+func respondOrderAddressError(c *gin.Context, route string, err error) {
+	var addressErr orderAddressError
+	if errors.As(err, &addressErr) {
+		respondWithError(c, http.StatusBadRequest, route, addressErr.Message)
+		return
+	}
+	respondWithError(c, http.StatusInternalServerError, route, "db error")
+}
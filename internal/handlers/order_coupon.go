@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// Reasons reported by couponError.
+const (
+	couponReasonNotFound      = "not_found"
+	couponReasonInactive      = "inactive"
+	couponReasonNotStarted    = "not_started"
+	couponReasonExpired       = "expired"
+	couponReasonUsageLimit    = "usage_limit"
+	couponReasonUserLimit     = "user_limit"
+	couponReasonLoginRequired = "login_required"
+	couponReasonMinBasket     = "min_basket"
+	couponReasonNotApplicable = "not_applicable"
+)
+
+type couponError struct {
+	Code      string
+	Reason    string
+	MinBasket float64
+}
+
+func (e couponError) Error() string {
+	return "coupon " + e.Reason
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyCoupon validates code against the priced order and writes the
+// discount onto it. Lines are counted after their promotion discounts. It
+// only reads; redeemCoupon records the use.
+func applyCoupon(ctx context.Context, db *mongo.Database, order *models.Order, products map[primitive.ObjectID]models.Product, code string) (models.Coupon, error) {
+	code = normalizeCouponCode(code)
+
+	var coupon models.Coupon
+	err := db.Collection("coupons").FindOne(ctx, bson.M{"code": code}).Decode(&coupon)
+	if err == mongo.ErrNoDocuments {
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonNotFound}
+	}
+	if err != nil {
+		return models.Coupon{}, err
+	}
+
+	now := time.Now()
+	switch {
+	case !coupon.IsActive:
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonInactive}
+	case coupon.StartsAt != nil && now.Before(*coupon.StartsAt):
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonNotStarted}
+	case coupon.EndsAt != nil && !now.Before(*coupon.EndsAt):
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonExpired}
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonUsageLimit}
+	}
+
+	if coupon.PerUserLimit > 0 {
+		if order.UserID == nil {
+			return models.Coupon{}, couponError{Code: code, Reason: couponReasonLoginRequired}
+		}
+		used, err := db.Collection("coupon_redemptions").CountDocuments(ctx, bson.M{
+			"couponId": coupon.ID,
+			"userId":   *order.UserID,
+		})
+		if err != nil {
+			return models.Coupon{}, err
+		}
+		if int(used) >= coupon.PerUserLimit {
+			return models.Coupon{}, couponError{Code: code, Reason: couponReasonUserLimit}
+		}
+	}
+
+	var basket, eligible float64
+	for _, item := range order.Items {
+		line := item.Price*item.Quantity - item.Discount
+		basket += line
+		if couponCoversProduct(coupon, products[item.ProductID]) {
+			eligible += line
+		}
+	}
+
+	if coupon.MinBasket > 0 && basket < coupon.MinBasket {
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonMinBasket, MinBasket: coupon.MinBasket}
+	}
+	if eligible <= 0 {
+		return models.Coupon{}, couponError{Code: code, Reason: couponReasonNotApplicable}
+	}
+
+	discount := coupon.Value
+	if coupon.Type == models.CouponTypePercentage {
+		discount = eligible * coupon.Value / 100
+		if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+			discount = coupon.MaxDiscount
+		}
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+	discount = roundPrice(discount)
+
+	order.Discount = roundPrice(order.Discount + discount)
+	order.TotalPrice = roundPrice(order.TotalPrice - discount)
+	order.Coupon = &models.OrderCoupon{
+		CouponID: coupon.ID,
+		Code:     coupon.Code,
+		Type:     coupon.Type,
+		Value:    coupon.Value,
+		Discount: discount,
+	}
+	return coupon, nil
+}
+
+// redeemCoupon counts the use against the global limit and records the
+// redemption. Run inside the order transaction: the usedCount increment makes
+// concurrent redemptions of the same coupon conflict and retry, so limits
+// cannot be overrun.
+func redeemCoupon(ctx context.Context, db *mongo.Database, coupon models.Coupon, order models.Order) error {
+	filter := bson.M{"_id": coupon.ID, "isActive": true}
+	if coupon.UsageLimit > 0 {
+		filter["usedCount"] = bson.M{"$lt": coupon.UsageLimit}
+	}
+
+	res, err := db.Collection("coupons").UpdateOne(ctx, filter, bson.M{
+		"$inc": bson.M{"usedCount": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return couponError{Code: coupon.Code, Reason: couponReasonUsageLimit}
+	}
+
+	_, err = db.Collection("coupon_redemptions").InsertOne(ctx, models.CouponRedemption{
+		CouponID:  coupon.ID,
+		Code:      coupon.Code,
+		UserID:    order.UserID,
+		OrderID:   order.ID,
+		Discount:  order.Coupon.Discount,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// releaseCoupon gives a cancelled or rejected order's coupon use back: the
+// redemption is removed and the global count decremented. Run inside the
+// cancelling transaction.
+func releaseCoupon(ctx context.Context, db *mongo.Database, order models.Order) error {
+	if order.Coupon == nil {
+		return nil
+	}
+
+	res, err := db.Collection("coupon_redemptions").DeleteOne(ctx, bson.M{
+		"couponId": order.Coupon.CouponID,
+		"orderId":  order.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return nil
+	}
+
+	_, err = db.Collection("coupons").UpdateOne(
+		ctx,
+		bson.M{"_id": order.Coupon.CouponID, "usedCount": bson.M{"$gt": 0}},
+		bson.M{
+			"$inc": bson.M{"usedCount": -1},
+			"$set": bson.M{"updatedAt": time.Now()},
+		},
+	)
+	return err
+}
+
+func couponCoversProduct(coupon models.Coupon, product models.Product) bool {
+	if len(coupon.ProductIDs) == 0 && len(coupon.Categories) == 0 {
+		return true
+	}
+	for _, id := range coupon.ProductIDs {
+		if id == product.ID {
+			return true
+		}
+	}
+	for _, category := range coupon.Categories {
+		for _, productCategory := range product.Category {
+			if strings.EqualFold(category, productCategory) {
+				return true
+			}
+		}
+	}
+	return false
+}
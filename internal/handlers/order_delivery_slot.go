@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/models"
+)
+
+type deliverySlotError struct {
+	SlotID string
+	// Reason is one of required, not_found, closed or full.
+	Reason string
+}
+
+func (e deliverySlotError) Error() string {
+	return "delivery slot " + e.Reason
+}
+
+// bookDeliverySlot books slotID for order inside the order transaction and
+// snapshots it on the order. An empty slotID is accepted unless the schedule
+// requires a slot.
+func bookDeliverySlot(ctx context.Context, db *mongo.Database, order *models.Order, slotID string, loc *time.Location) error {
+	slotID = strings.TrimSpace(slotID)
+
+	schedule, err := delivery.LoadSlotSchedule(ctx, db)
+	if err != nil {
+		return err
+	}
+	if slotID == "" {
+		if schedule.Required && len(schedule.Windows) > 0 {
+			return deliverySlotError{Reason: "required"}
+		}
+		return nil
+	}
+
+	slot, err := delivery.FindSlot(schedule, slotID, time.Now(), loc)
+	switch {
+	case errors.Is(err, delivery.ErrSlotNotFound):
+		return deliverySlotError{SlotID: slotID, Reason: "not_found"}
+	case errors.Is(err, delivery.ErrSlotClosed):
+		return deliverySlotError{SlotID: slotID, Reason: "closed"}
+	case err != nil:
+		return err
+	}
+
+	if err := delivery.BookSlot(ctx, db, slot); err != nil {
+		if errors.Is(err, delivery.ErrSlotFull) {
+			return deliverySlotError{SlotID: slotID, Reason: "full"}
+		}
+		return err
+	}
+
+	order.DeliverySlot = &models.OrderDeliverySlot{
+		ID:       slot.ID,
+		StartsAt: slot.StartsAt,
+		EndsAt:   slot.EndsAt,
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// parseStatusFilter turns a comma separated ?status= value into a Mongo
+// condition. An empty value yields nil.
+func parseStatusFilter(raw string) (interface{}, error) {
+	statuses := make([]string, 0)
+	for _, part := range strings.Split(raw, ",") {
+		status := strings.ToLower(strings.TrimSpace(part))
+		if status == "" {
+			continue
+		}
+		if !models.IsValidOrderStatus(status) {
+			return nil, errors.New("invalid status")
+		}
+		statuses = append(statuses, status)
+	}
+
+	switch len(statuses) {
+	case 0:
+		return nil, nil
+	case 1:
+		return statuses[0], nil
+	default:
+		return bson.M{"$in": statuses}, nil
+	}
+}
+
+// findOrdersPage returns one page of orders matching filter, newest first,
+// together with the total match count.
+func findOrdersPage(ctx context.Context, db *mongo.Database, filter bson.M, page, limit int64) ([]models.Order, int64, error) {
+	return findOrdersPageIn(ctx, db.Collection("orders"), filter, page, limit)
+}
+
+// findOrdersPageIn is findOrdersPage for an arbitrary order collection, such
+// as orders_archive.
+func findOrdersPageIn(ctx context.Context, collection *mongo.Collection, filter bson.M, page, limit int64) ([]models.Order, int64, error) {
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip((page - 1) * limit).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := make([]models.Order, 0)
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// summarizeOrders counts the orders matching filter per status and sums the
+// revenue of those that were not cancelled or rejected. Revenue is net of
+// refunds.
+func summarizeOrders(ctx context.Context, db *mongo.Database, filter bson.M) (gin.H, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+			"revenue": bson.M{"$sum": bson.M{"$subtract": bson.A{
+				"$totalPrice",
+				bson.M{"$ifNull": bson.A{"$refundedAmount", 0}},
+			}}},
+		}}},
+	}
+
+	cursor, err := db.Collection("orders").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Status  string  `bson:"_id"`
+		Count   int64   `bson:"count"`
+		Revenue float64 `bson:"revenue"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	byStatus := gin.H{}
+	var revenue float64
+	for _, g := range groups {
+		byStatus[g.Status] = g.Count
+		if g.Status != models.OrderStatusCancelled && g.Status != models.OrderStatusRejected {
+			revenue += g.Revenue
+		}
+	}
+
+	return gin.H{
+		"byStatus":     byStatus,
+		"totalRevenue": roundPrice(revenue),
+	}, nil
+}
+
+func paginationResponse(page, limit, total int64) gin.H {
+	totalPages := int64(0)
+	if total > 0 {
+		totalPages = int64(math.Ceil(float64(total) / float64(limit)))
+	}
+	return gin.H{
+		"page":       page,
+		"limit":      limit,
+		"total":      total,
+		"totalPages": totalPages,
+	}
+}
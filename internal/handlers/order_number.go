@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// orderNumberBase offsets the global counter so numbers look like "#100001"
+// from the first order on.
+const orderNumberBase = 100000
+
+// nextOrderNumber atomically increments the order counter and formats the
+// next order number. With daily set, the counter restarts every day and the
+// number is prefixed with the date, e.g. "20261018-0042". Pass a session
+// context to make the number part of the order transaction.
+func nextOrderNumber(ctx context.Context, db *mongo.Database, now time.Time, daily bool) (string, error) {
+	counterID := "orders"
+	if daily {
+		counterID = "orders:" + now.Format("20060102")
+	}
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := db.Collection("counters").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": counterID},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return "", err
+	}
+
+	if daily {
+		return fmt.Sprintf("%s-%04d", now.Format("20060102"), counter.Seq), nil
+	}
+	return fmt.Sprintf("%d", orderNumberBase+counter.Seq), nil
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/models"
+	"backend/internal/promotions"
+)
+
+// priceTolerance absorbs float rounding differences between the client's
+// displayed total and the server-computed one.
+const priceTolerance = 0.01
+
+// priceOrderItems loads every product referenced by the order and fills each
+// line's name, price, unit and campaign state from the stored document and the
+// active campaigns, so the client payload never decides what an order costs.
+// Quantities are checked against the product's unit rules, and grouped
+// products must be ordered through one of their variants.
+// The loaded products are returned keyed by id for the caller's stock checks.
+func priceOrderItems(ctx context.Context, db *mongo.Database, order *models.Order) (map[primitive.ObjectID]models.Product, error) {
+	products := make(map[primitive.ObjectID]models.Product, len(order.Items))
+	var total float64
+
+	campaigns, err := promotions.ActiveCampaigns(ctx, db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		product, ok := products[item.ProductID]
+		if !ok {
+			var raw bson.M
+			err := db.Collection("products").FindOne(
+				ctx,
+				bson.M{
+					"_id":       item.ProductID,
+					"isDeleted": bson.M{"$ne": true},
+				},
+			).Decode(&raw)
+			if err == mongo.ErrNoDocuments {
+				return nil, productNotFoundError{ProductID: item.ProductID}
+			}
+			if err != nil {
+				return nil, err
+			}
+			if product, err = normalizeProductDocument(raw); err != nil {
+				return nil, err
+			}
+			promotions.ApplyCampaigns(&product, campaigns)
+			products[item.ProductID] = product
+		}
+
+		if !product.IsActive {
+			return nil, productUnavailableError{ProductID: item.ProductID}
+		}
+		if product.HasVariants {
+			return nil, variantRequiredError{ProductID: item.ProductID}
+		}
+		if item.ParentID != nil && (product.ParentID == nil || *product.ParentID != *item.ParentID) {
+			return nil, productNotFoundError{ProductID: item.ProductID}
+		}
+		if err := product.CheckQuantity(item.Quantity); err != nil {
+			return nil, invalidQuantityError{ProductID: item.ProductID, Message: err.Error()}
+		}
+
+		item.Name = product.Name
+		item.Unit = product.UnitOrDefault()
+		item.ParentID = product.ParentID
+		item.VariantName = product.VariantName
+		item.Price = product.Price
+		item.IsCampaign = product.IsCampaign
+		item.OriginalPrice = 0
+		item.CampaignID = product.CampaignID
+		if product.IsCampaign {
+			item.OriginalPrice = product.OriginalPrice
+		}
+		total += item.Price * item.Quantity
+	}
+
+	order.Subtotal = roundPrice(total)
+	order.TotalPrice = order.Subtotal
+	return products, nil
+}
+
+// pricedOrder carries what priceOrder loaded for the writes that follow.
+type pricedOrder struct {
+	Products map[primitive.ObjectID]models.Product
+	// Coupon is set when a coupon was applied and must be redeemed.
+	Coupon *models.Coupon
+	// Delivery is the pricing the delivery fee was computed with.
+	Delivery models.DeliveryPricing
+	// Zone is the delivery zone of the customer's location, if any.
+	Zone *models.DeliveryZone
+}
+
+// priceOrder runs the whole read-only pricing pipeline on order: product
+// prices and campaigns, multi-buy and bundle promotions, the coupon, then the
+// delivery fee of the customer's delivery zone. Orders and quotes both go through it so they can never
+// disagree. Without coordinates the general delivery pricing applies; orders
+// must give them while zones are defined (see requireDeliveryLocation). The
+// minimum order amount is left to the caller; see checkMinimumOrder. Fields derived by an earlier run are reset first.
+func priceOrder(ctx context.Context, db *mongo.Database, order *models.Order, couponCode string) (pricedOrder, error) {
+	resetOrderPricing(order)
+
+	products, err := priceOrderItems(ctx, db, order)
+	if err != nil {
+		return pricedOrder{}, err
+	}
+	priced := pricedOrder{Products: products}
+
+	if err := applyOrderPromotions(ctx, db, order, products); err != nil {
+		return pricedOrder{}, err
+	}
+
+	if strings.TrimSpace(couponCode) != "" {
+		coupon, err := applyCoupon(ctx, db, order, products, couponCode)
+		if err != nil {
+			return pricedOrder{}, err
+		}
+		priced.Coupon = &coupon
+	}
+
+	settings, err := delivery.LoadSettings(ctx, db)
+	if err != nil {
+		return pricedOrder{}, err
+	}
+	zone, served, err := locateDeliveryZone(ctx, db, order.Customer.Latitude, order.Customer.Longitude)
+	if err != nil {
+		return pricedOrder{}, err
+	}
+	if !served {
+		return pricedOrder{}, outsideDeliveryZoneError{}
+	}
+
+	var override *models.DeliveryPricing
+	if zone != nil {
+		priced.Zone = zone
+		override = zone.Pricing
+		order.DeliveryZone = &models.OrderDeliveryZone{ZoneID: zone.ID, Name: zone.Name}
+	}
+	priced.Delivery = delivery.Resolve(settings, override)
+	applyDeliveryFee(order, priced.Delivery)
+
+	return priced, nil
+}
+
+// applyDeliveryFee adds the delivery fee for the discounted basket.
+func applyDeliveryFee(order *models.Order, pricing models.DeliveryPricing) {
+	goods := roundPrice(order.Subtotal - order.Discount)
+	order.DeliveryFee = delivery.Fee(pricing, goods)
+	order.TotalPrice = roundPrice(goods + order.DeliveryFee)
+}
+
+// resetOrderPricing clears everything priceOrder derives, so pricing the same
+// order again, as a retried order transaction does, starts from the bare
+// lines instead of adding to the last result.
+func resetOrderPricing(order *models.Order) {
+	order.Subtotal = 0
+	order.Discount = 0
+	order.DeliveryFee = 0
+	order.TotalPrice = 0
+	order.Promotions = nil
+	order.Coupon = nil
+	order.DeliveryZone = nil
+	for i := range order.Items {
+		order.Items[i].Discount = 0
+	}
+}
+
+// checkMinimumOrder rejects baskets below the minimum order amount.
+func checkMinimumOrder(order models.Order, pricing models.DeliveryPricing) error {
+	goods := roundPrice(order.Subtotal - order.Discount)
+	if !delivery.MeetsMinimum(pricing, goods) {
+		return minimumOrderError{Minimum: pricing.MinOrderAmount, Current: goods}
+	}
+	return nil
+}
+
+// applyOrderPromotions evaluates the active promotions against the priced
+// lines and records them line by line on the order.
+func applyOrderPromotions(ctx context.Context, db *mongo.Database, order *models.Order, products map[primitive.ObjectID]models.Product) error {
+	rules, err := promotions.ActivePromotions(ctx, db, time.Now())
+	if err != nil {
+		return err
+	}
+
+	order.Promotions = promotions.EvaluatePromotions(order.Items, products, rules)
+	discounts := promotions.LineDiscounts(order.Promotions)
+
+	var total float64
+	for i := range order.Items {
+		order.Items[i].Discount = discounts[order.Items[i].ProductID]
+		total += order.Items[i].Discount
+	}
+
+	order.Discount = roundPrice(order.Discount + total)
+	order.TotalPrice = roundPrice(order.TotalPrice - total)
+	return nil
+}
+
+// verifyClientTotal rejects orders whose client-side total disagrees with the
+// server price. A missing total is accepted; the server price applies.
+func verifyClientTotal(submitted *float64, order models.Order) error {
+	if submitted == nil {
+		return nil
+	}
+	if math.Abs(*submitted-order.TotalPrice) >= priceTolerance {
+		return priceMismatchError{Expected: order.TotalPrice, Submitted: *submitted}
+	}
+	return nil
+}
+
+func roundPrice(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+type productUnavailableError struct {
+	ProductID primitive.ObjectID
+}
+
+func (e productUnavailableError) Error() string {
+	return "product unavailable"
+}
+
+// variantRequiredError reports an order line for a grouped product instead
+// of one of its variants.
+type variantRequiredError struct {
+	ProductID primitive.ObjectID
+}
+
+func (e variantRequiredError) Error() string {
+	return "product variant required"
+}
+
+// invalidQuantityError reports a quantity the product's unit rules do not
+// allow.
+type invalidQuantityError struct {
+	ProductID primitive.ObjectID
+	Message   string
+}
+
+func (e invalidQuantityError) Error() string {
+	return e.Message
+}
+
+type minimumOrderError struct {
+	Minimum float64
+	Current float64
+}
+
+func (e minimumOrderError) Error() string {
+	return "order below minimum amount"
+}
+
+type priceMismatchError struct {
+	Expected  float64
+	Submitted float64
+}
+
+func (e priceMismatchError) Error() string {
+	return "order total mismatch"
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// testDatabase connects to the MongoDB in MONGO_TEST_URI and returns a fresh
+// database that is dropped after the test. Tests needing one are skipped
+// when the variable is not set.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database("test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return db
+}
+
+// A retried order transaction prices the same order again; every run must
+// give the result of the first instead of stacking discounts on it.
+func TestPriceOrderTwice(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+	bread := primitive.NewObjectID()
+
+	seed := map[string]interface{}{
+		"products": bson.M{
+			"_id": bread, "name": "Ekmek", "price": 10.0, "stock": 50.0,
+			"category": bson.A{"fırın"}, "isActive": true,
+		},
+		"promotions": models.Promotion{
+			ID: primitive.NewObjectID(), Name: "3 al 2 öde", Type: models.PromotionMultiBuy,
+			BuyQuantity: 3, PayQuantity: 2, Categories: []string{"fırın"},
+			StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), IsActive: true,
+		},
+		"coupons": models.Coupon{
+			ID: primitive.NewObjectID(), Code: "IKI", Type: models.CouponTypeFixed, Value: 2, IsActive: true,
+		},
+		"settings": models.DeliverySettings{ID: "delivery", DeliveryPricing: models.DeliveryPricing{Fee: 5}},
+	}
+	for collection, document := range seed {
+		if _, err := db.Collection(collection).InsertOne(ctx, document); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order := models.Order{Items: []models.OrderItem{{ProductID: bread, Quantity: 3}}}
+	for run := 1; run <= 2; run++ {
+		if _, err := priceOrder(ctx, db, &order, "iki"); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if order.Subtotal != 30 || order.Discount != 12 || order.TotalPrice != 23 {
+			t.Errorf("run %d: subtotal=%v discount=%v total=%v, want 30, 12 and 23",
+				run, order.Subtotal, order.Discount, order.TotalPrice)
+		}
+		if len(order.Promotions) != 1 || order.Items[0].Discount != 10 {
+			t.Errorf("run %d: promotions=%d line discount=%v, want 1 and 10",
+				run, len(order.Promotions), order.Items[0].Discount)
+		}
+	}
+
+	// Pricing again without the coupon, as the quote does after a coupon
+	// error, must drop the coupon of the previous run.
+	if _, err := priceOrder(ctx, db, &order, ""); err != nil {
+		t.Fatal(err)
+	}
+	if order.Coupon != nil || order.Discount != 10 || order.TotalPrice != 25 {
+		t.Errorf("without coupon: coupon=%v discount=%v total=%v, want none, 10 and 25",
+			order.Coupon, order.Discount, order.TotalPrice)
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/inventory"
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+var (
+	errOrderNotFound      = errors.New("order not found")
+	errOrderStatusChanged = errors.New("order status changed, retry")
+)
+
+type orderTransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e orderTransitionError) Error() string {
+	return "invalid status transition"
+}
+
+// transitionOrder moves the order matching filter to change.To inside a
+// transaction and appends change to its status history. When allowedFrom is
+// given the current status must be one of them. Cancelled and rejected orders
+// give the stock of every item, their delivery slot and coupon use back; paid
+// card orders also get a pending full refund, which the caller completes with
+// the payment provider after the commit (see completePendingRefunds).
+func transitionOrder(ctx context.Context, db *mongo.Database, filter bson.M, change models.OrderStatusChange, allowedFrom ...string) (models.Order, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return models.Order{}, err
+	}
+	defer session.EndSession(ctx)
+
+	var updated models.Order
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		var order models.Order
+		err := db.Collection("orders").FindOne(sessCtx, filter).Decode(&order)
+		if err == mongo.ErrNoDocuments {
+			return nil, errOrderNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !models.CanTransitionOrderStatus(order.Status, change.To) || !statusAllowed(allowedFrom, order.Status) {
+			return nil, orderTransitionError{
+				From:    order.Status,
+				To:      change.To,
+				Allowed: models.NextOrderStatuses(order.Status),
+			}
+		}
+
+		// Card orders are confirmed by the payment webhook once paid.
+		if change.To == models.OrderStatusConfirmed && order.PaymentMethod == "card" &&
+			order.PaymentStatus != "" && order.PaymentStatus != models.PaymentStatusPaid {
+			return nil, errPaymentNotCompleted
+		}
+
+		if change.ChangedAt.IsZero() {
+			change.ChangedAt = time.Now()
+		}
+		change.From = order.Status
+
+		set := bson.M{"status": change.To, "updatedAt": change.ChangedAt}
+		if change.To == models.OrderStatusCancelled {
+			set["cancelReason"] = change.Note
+			set["cancelledAt"] = change.ChangedAt
+		}
+		if change.To == models.OrderStatusDelivered && order.PaymentMethod == "cash" && order.PaymentStatus != "" {
+			set["paymentStatus"] = models.PaymentStatusPaid
+		}
+
+		// Filtering on the current status makes concurrent transitions fail
+		// instead of silently overwriting each other.
+		res, err := db.Collection("orders").UpdateOne(
+			sessCtx,
+			bson.M{"_id": order.ID, "status": order.Status},
+			bson.M{
+				"$set":  set,
+				"$push": bson.M{"statusHistory": change},
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, errOrderStatusChanged
+		}
+
+		if change.To == models.OrderStatusCancelled || change.To == models.OrderStatusRejected {
+			if err := restoreOrderStock(sessCtx, db, order, change); err != nil {
+				return nil, err
+			}
+			if order.DeliverySlot != nil {
+				if err := delivery.ReleaseSlot(sessCtx, db, order.DeliverySlot.ID); err != nil {
+					return nil, err
+				}
+			}
+			if err := releaseCoupon(sessCtx, db, order); err != nil {
+				return nil, err
+			}
+			if order.PaymentMethod == "card" && order.PaymentStatus == models.PaymentStatusPaid {
+				if err := recordCancellationRefund(sessCtx, db, order, change); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return nil, db.Collection("orders").FindOne(sessCtx, bson.M{"_id": order.ID}).Decode(&updated)
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return updated, nil
+}
+
+// restoreOrderStock gives the quantities of the order's items back to their
+// products and records each return in the stock ledger. Refunded units were
+// already handled by the refund. Substitutes and weighed overage are only
+// taken from stock when picking completes, so they are returned once the
+// order was picked.
+func restoreOrderStock(ctx context.Context, db *mongo.Database, order models.Order, change models.OrderStatusChange) error {
+	for _, item := range order.Items {
+		quantity := item.Quantity - item.RefundedQuantity
+		if order.PickedAt != nil && item.Picking != nil {
+			quantity += item.Picking.OverQuantity
+		}
+		if quantity > 0 {
+			if err := restockForCancel(ctx, db, order, item.ProductID, models.RoundQuantity(quantity), change.Note, change); err != nil {
+				return err
+			}
+		}
+
+		if order.PickedAt == nil || item.Picking == nil || item.Picking.Substitute == nil {
+			continue
+		}
+		sub := item.Picking.Substitute
+		if err := restockForCancel(ctx, db, order, sub.ProductID, sub.Quantity, "substitute for "+item.Name, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restockForCancel(ctx context.Context, db *mongo.Database, order models.Order, productID primitive.ObjectID, quantity float64, note string, change models.OrderStatusChange) error {
+	_, err := inventory.AdjustStock(ctx, db, productID, quantity, nil, models.StockMovement{
+		Reason:    models.StockReasonCancel,
+		ActorType: change.ActorType,
+		ActorID:   change.ActorID,
+		OrderID:   &order.ID,
+		Note:      note,
+	})
+	return err
+}
+
+// recordCancellationRefund adds a pending refund of everything not refunded
+// yet to a cancelled or rejected card order. The stock was already returned
+// by the cancellation.
+func recordCancellationRefund(ctx context.Context, db *mongo.Database, order models.Order, change models.OrderStatusChange) error {
+	if roundPrice(order.TotalPrice-order.RefundedAmount) <= 0 {
+		return nil
+	}
+	refund, err := buildRefund(&order, nil, true)
+	if err != nil {
+		return err
+	}
+	refund.Reason = "order " + change.To
+	if change.Note != "" {
+		refund.Reason += ": " + change.Note
+	}
+	refund.Restocked = true
+	refund.ActorID = change.ActorID
+	refund.Status = models.RefundStatusPending
+	refund.CreatedAt = change.ChangedAt
+
+	_, err = db.Collection("orders").UpdateOne(
+		ctx,
+		bson.M{"_id": order.ID},
+		bson.M{
+			"$set": bson.M{
+				"items":          order.Items,
+				"refundedAmount": roundPrice(order.RefundedAmount + refund.Amount),
+			},
+			"$push": bson.M{"refunds": refund},
+		},
+	)
+	return err
+}
+
+// completePendingRefunds sends the order's pending card refunds to the
+// provider and returns the order with their outcome.
+func completePendingRefunds(ctx context.Context, db *mongo.Database, provider payments.Provider, order models.Order) models.Order {
+	for _, refund := range order.Refunds {
+		if refund.Status == models.RefundStatusPending {
+			var err error
+			if order, _, err = completeCardRefund(ctx, db, provider, order, refund); err != nil {
+				log.Println("[PAYMENT] [ERROR] card refund not sent:", err)
+			}
+		}
+	}
+	return order
+}
+
+// statusAllowed reports whether status is in allowed; an empty list allows
+// every status.
+func statusAllowed(allowed []string, status string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, s := range allowed {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// respondOrderTransitionError maps transitionOrder errors to HTTP responses.
+func respondOrderTransitionError(c *gin.Context, err error) {
+	var transitionErr orderTransitionError
+	switch {
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	case errors.Is(err, errOrderStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errPaymentNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Kart ödemesi tamamlanmadan sipariş onaylanamaz"})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+	}
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+const paymentCurrency = "TRY"
+
+// A failed capture is retried after captureRetryDelay times the attempts so
+// far; the payment fails on the maxCaptureAttempts-th failure.
+const (
+	maxCaptureAttempts = 5
+	captureRetryDelay  = 5 * time.Minute
+)
+
+var errPaymentNotCompleted = errors.New("card payment not completed")
+
+// paymentView is what checkout returns for a card order; the app sends the
+// customer to RedirectURL for 3-D Secure.
+type paymentView struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	RedirectURL string `json:"redirectUrl"`
+}
+
+// startCardPayment initiates the payment of a placed card order. When the
+// payment cannot be started for any reason, the payment and order are marked
+// failed and the order is cancelled so its stock is released.
+func startCardPayment(ctx context.Context, db *mongo.Database, provider payments.Provider, order models.Order) (models.Payment, error) {
+	now := time.Now()
+	payment := models.Payment{
+		ID:        primitive.NewObjectID(),
+		OrderID:   order.ID,
+		Provider:  provider.Name(),
+		Amount:    order.TotalPrice,
+		Currency:  paymentCurrency,
+		Status:    payments.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := db.Collection("payments").InsertOne(ctx, payment); err != nil {
+		log.Println("[PAYMENT] [ERROR] store payment failed:", err)
+		abandonCardOrder(db, order.ID, func(ctx context.Context) {
+			cancelUnpaidOrder(ctx, db, order.ID, "payment not started")
+		})
+		return models.Payment{}, err
+	}
+
+	initiation, err := provider.Initiate(ctx, payments.InitiateRequest{
+		Reference: payment.ID.Hex(),
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+	})
+	if err != nil {
+		log.Println("[PAYMENT] [ERROR] initiate failed:", err)
+		abandonCardOrder(db, order.ID, func(ctx context.Context) {
+			failPayment(ctx, db, payment, "initiate_failed")
+		})
+		return models.Payment{}, err
+	}
+
+	payment.ProviderPaymentID = initiation.ProviderPaymentID
+	payment.RedirectURL = initiation.RedirectURL
+	payment.UpdatedAt = time.Now()
+	_, err = db.Collection("payments").UpdateByID(ctx, payment.ID, bson.M{"$set": bson.M{
+		"providerPaymentId": payment.ProviderPaymentID,
+		"redirectUrl":       payment.RedirectURL,
+		"updatedAt":         payment.UpdatedAt,
+	}})
+	if err != nil {
+		log.Println("[PAYMENT] [ERROR] store initiation failed:", err)
+		abandonCardOrder(db, order.ID, func(ctx context.Context) {
+			failPayment(ctx, db, payment, "initiation_not_stored")
+		})
+		return models.Payment{}, err
+	}
+	return payment, nil
+}
+
+// abandonCardOrder runs the cleanup of a card order whose payment could not
+// be started. It uses its own context because the request context may be
+// what failed.
+func abandonCardOrder(db *mongo.Database, orderID primitive.ObjectID, cleanup func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cleanup(ctx)
+	log.Println("[PAYMENT] [INFO] card order abandoned:", orderID.Hex())
+}
+
+// orderCreatedResponse builds the checkout response for a placed order and
+// starts the card payment of card orders. It responds itself and returns
+// false when the payment could not be started.
+func orderCreatedResponse(c *gin.Context, ctx context.Context, db *mongo.Database, provider payments.Provider, route string, order models.Order) (gin.H, bool) {
+	resp := gin.H{
+		"orderId":       order.ID.Hex(),
+		"number":        order.Number,
+		"subtotal":      order.Subtotal,
+		"discount":      order.Discount,
+		"deliveryFee":   order.DeliveryFee,
+		"totalPrice":    order.TotalPrice,
+		"paymentStatus": order.PaymentStatus,
+		"message":       "order created",
+	}
+	if order.PaymentMethod != "card" {
+		return resp, true
+	}
+
+	payment, err := startCardPayment(ctx, db, provider, order)
+	if err != nil {
+		respondCardPaymentError(c, route, order)
+		return nil, false
+	}
+	resp["payment"] = paymentView{
+		ID:          payment.ID.Hex(),
+		Status:      payment.Status,
+		RedirectURL: payment.RedirectURL,
+	}
+	return resp, true
+}
+
+// respondCardPaymentError answers a checkout whose order was stored but
+// whose card payment could not be started; startCardPayment has cancelled
+// the order by then. The status is not a 5xx so an idempotent retry replays
+// this answer instead of placing the order again.
+func respondCardPaymentError(c *gin.Context, route string, order models.Order) {
+	log.Printf("[%s] [ERROR] card payment not started for order %s", route, order.ID.Hex())
+	c.JSON(http.StatusPaymentRequired, gin.H{
+		"error":         "Ödeme başlatılamadı, sipariş iptal edildi",
+		"orderId":       order.ID.Hex(),
+		"status":        models.OrderStatusCancelled,
+		"paymentStatus": models.PaymentStatusFailed,
+	})
+}
+
+/*
+GET|POST /payments/:id/3ds
+- Müşteri 3-D Secure sonrası ödeme sağlayıcısından buraya döner
+- Ödeme onaylanırsa tahsilat istenir; sipariş sadece doğrulanmış webhook ile onaylanır
+*/
+func PaymentCallback(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /payments/:id/3ds"
+		defer handlePanic(c, route)
+
+		paymentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid payment id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var payment models.Payment
+		err = db.Collection("payments").FindOne(ctx, bson.M{"_id": paymentID}).Decode(&payment)
+		if err == mongo.ErrNoDocuments {
+			respondWithError(c, http.StatusNotFound, route, "payment not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		if err := c.Request.ParseForm(); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid callback")
+			return
+		}
+
+		if payment.Status == payments.StatusPending {
+			result, err := provider.Complete3DS(ctx, payment.ProviderPaymentID, c.Request.Form)
+			if err != nil {
+				log.Println("[PAYMENT] [ERROR] 3ds completion failed:", err)
+				respondWithError(c, http.StatusBadGateway, route, "payment provider error")
+				return
+			}
+
+			switch result.Status {
+			case payments.StatusAuthorized:
+				if err := authorizePayment(ctx, db, payment); err != nil {
+					respondWithError(c, http.StatusInternalServerError, route, "db error")
+					return
+				}
+				payment.Status = payments.StatusAuthorized
+
+				// The capture outcome arrives as a webhook; a failed
+				// capture is recorded and retried by the capture job.
+				if err := capturePayment(ctx, db, provider, payment); err != nil {
+					log.Println("[PAYMENT] [ERROR] capture failed:", err)
+				}
+			case payments.StatusFailed:
+				failPayment(ctx, db, payment, result.FailureReason)
+				payment.Status = payments.StatusFailed
+			}
+		}
+
+		var order models.Order
+		if err := db.Collection("orders").FindOne(ctx, bson.M{"_id": payment.OrderID}).Decode(&order); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"orderId":       order.ID.Hex(),
+			"orderStatus":   order.Status,
+			"paymentStatus": order.PaymentStatus,
+		})
+	}
+}
+
+/*
+POST /payments/webhook
+- Ödeme sağlayıcısının imzalı bildirimleri; imza doğrulanamazsa 401
+- payment.captured siparişi paid + confirmed yapar, payment.failed iptal eder
+*/
+func PaymentWebhook(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /payments/webhook"
+		defer handlePanic(c, route)
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		event, err := provider.ParseWebhook(c.Request.Header, body)
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			log.Println("[PAYMENT] [WARN] webhook with invalid signature rejected")
+			respondWithError(c, http.StatusUnauthorized, route, "invalid signature")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid event")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var payment models.Payment
+		err = db.Collection("payments").FindOne(ctx, bson.M{
+			"provider":          provider.Name(),
+			"providerPaymentId": event.ProviderPaymentID,
+		}).Decode(&payment)
+		if err == mongo.ErrNoDocuments {
+			respondWithError(c, http.StatusNotFound, route, "payment not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		if err := applyPaymentEvent(ctx, db, payment, event); err != nil {
+			log.Println("[PAYMENT] [ERROR] webhook handling failed:", err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		log.Printf("[PAYMENT] [INFO] webhook %s for payment %s", event.Type, payment.ID.Hex())
+		c.JSON(http.StatusOK, gin.H{"received": true})
+	}
+}
+
+// applyPaymentEvent moves the payment and its order on a verified event.
+// Every step only applies from the expected previous status, so replayed or
+// out-of-order events are harmless.
+func applyPaymentEvent(ctx context.Context, db *mongo.Database, payment models.Payment, event payments.Event) error {
+	switch event.Type {
+	case payments.EventAuthorized:
+		return authorizePayment(ctx, db, payment)
+
+	case payments.EventCaptured:
+		if err := setPaymentStatus(ctx, db, payment.ID, payments.StatusCaptured, "", payments.StatusPending, payments.StatusAuthorized); err != nil {
+			return err
+		}
+		if err := setOrderPaymentStatus(ctx, db, payment.OrderID, models.PaymentStatusPaid, models.PaymentStatusUnpaid, models.PaymentStatusAuthorized); err != nil {
+			return err
+		}
+		_, err := transitionOrder(ctx, db, bson.M{"_id": payment.OrderID}, models.OrderStatusChange{
+			To:        models.OrderStatusConfirmed,
+			ActorType: models.OrderActorSystem,
+			Note:      "payment captured",
+		}, models.OrderStatusPending)
+		var transitionErr orderTransitionError
+		if errors.As(err, &transitionErr) {
+			log.Printf("[PAYMENT] [WARN] captured payment %s for order in status %s", payment.ID.Hex(), transitionErr.From)
+			return nil
+		}
+		return err
+
+	case payments.EventFailed:
+		failPayment(ctx, db, payment, event.FailureReason)
+		return nil
+
+	case payments.EventRefunded:
+		return nil
+	}
+
+	log.Println("[PAYMENT] [WARN] unknown webhook event:", event.Type)
+	return nil
+}
+
+// capturePayment asks the provider to capture an authorised payment. A
+// failure is stored on the payment with the time of the next attempt; after
+// maxCaptureAttempts failures the payment fails and its order is cancelled.
+func capturePayment(ctx context.Context, db *mongo.Database, provider payments.Provider, payment models.Payment) error {
+	_, captureErr := provider.Capture(ctx, payment.ProviderPaymentID, payment.Amount)
+	now := time.Now()
+
+	if captureErr == nil {
+		if payment.CaptureError == "" {
+			return nil
+		}
+		_, err := db.Collection("payments").UpdateOne(ctx, bson.M{"_id": payment.ID}, bson.M{
+			"$set":   bson.M{"updatedAt": now},
+			"$unset": bson.M{"captureError": "", "nextCaptureAt": ""},
+		})
+		return err
+	}
+
+	attempts := payment.CaptureAttempts + 1
+	if attempts >= maxCaptureAttempts {
+		failPayment(ctx, db, payment, "capture failed: "+captureErr.Error())
+		return captureErr
+	}
+
+	_, err := db.Collection("payments").UpdateOne(
+		ctx,
+		bson.M{"_id": payment.ID, "status": payments.StatusAuthorized},
+		bson.M{"$set": bson.M{
+			"captureAttempts": attempts,
+			"captureError":    captureErr.Error(),
+			"nextCaptureAt":   now.Add(time.Duration(attempts) * captureRetryDelay),
+			"updatedAt":       now,
+		}},
+	)
+	if err != nil {
+		log.Println("[PAYMENT] [ERROR] record capture failure:", err)
+	}
+	return captureErr
+}
+
+// RetryPaymentCaptures captures the authorised payments whose earlier
+// capture failed and whose retry is due, and returns how many the provider
+// accepted.
+func RetryPaymentCaptures(ctx context.Context, db *mongo.Database, provider payments.Provider) (int, error) {
+	cursor, err := db.Collection("payments").Find(ctx, bson.M{
+		"status":        payments.StatusAuthorized,
+		"nextCaptureAt": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var due []models.Payment
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	captured := 0
+	for _, payment := range due {
+		if err := capturePayment(ctx, db, provider, payment); err != nil {
+			log.Printf("[PAYMENT] [ERROR] capture retry %d failed for payment %s: %v", payment.CaptureAttempts+1, payment.ID.Hex(), err)
+			continue
+		}
+		captured++
+	}
+	return captured, nil
+}
+
+func authorizePayment(ctx context.Context, db *mongo.Database, payment models.Payment) error {
+	if err := setPaymentStatus(ctx, db, payment.ID, payments.StatusAuthorized, "", payments.StatusPending); err != nil {
+		return err
+	}
+	return setOrderPaymentStatus(ctx, db, payment.OrderID, models.PaymentStatusAuthorized, models.PaymentStatusUnpaid)
+}
+
+// failPayment marks the payment and order failed and cancels the order while
+// it is still pending. Errors are logged; the provider retries webhooks and
+// the order stays visible to admins.
+func failPayment(ctx context.Context, db *mongo.Database, payment models.Payment, reason string) {
+	if err := setPaymentStatus(ctx, db, payment.ID, payments.StatusFailed, reason, payments.StatusPending, payments.StatusAuthorized); err != nil {
+		log.Println("[PAYMENT] [ERROR] mark payment failed:", err)
+		return
+	}
+	cancelUnpaidOrder(ctx, db, payment.OrderID, "payment failed")
+}
+
+// cancelUnpaidOrder marks a pending card order's payment failed and cancels
+// it, giving its stock and delivery slot back.
+func cancelUnpaidOrder(ctx context.Context, db *mongo.Database, orderID primitive.ObjectID, note string) {
+	if err := setOrderPaymentStatus(ctx, db, orderID, models.PaymentStatusFailed, models.PaymentStatusUnpaid, models.PaymentStatusAuthorized); err != nil {
+		log.Println("[PAYMENT] [ERROR] mark order payment failed:", err)
+		return
+	}
+
+	_, err := transitionOrder(ctx, db, bson.M{"_id": orderID}, models.OrderStatusChange{
+		To:        models.OrderStatusCancelled,
+		ActorType: models.OrderActorSystem,
+		Note:      note,
+	}, models.OrderStatusPending)
+	if err != nil && !errors.As(err, &orderTransitionError{}) {
+		log.Println("[PAYMENT] [ERROR] cancel order after failed payment:", err)
+	}
+}
+
+func setPaymentStatus(ctx context.Context, db *mongo.Database, paymentID primitive.ObjectID, status, reason string, from ...string) error {
+	set := bson.M{"status": status, "updatedAt": time.Now()}
+	if reason != "" {
+		set["failureReason"] = reason
+	}
+	_, err := db.Collection("payments").UpdateOne(
+		ctx,
+		bson.M{"_id": paymentID, "status": bson.M{"$in": from}},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+func setOrderPaymentStatus(ctx context.Context, db *mongo.Database, orderID primitive.ObjectID, status string, from ...string) error {
+	_, err := db.Collection("orders").UpdateOne(
+		ctx,
+		bson.M{"_id": orderID, "paymentStatus": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"paymentStatus": status, "updatedAt": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/payments"
+)
+
+// Unverified webhooks are answered before the database is touched, so the
+// handler runs here without one.
+func TestPaymentWebhookRejectsUnverifiedEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := payments.NewFakeProvider(payments.Config{WebhookSecret: "s3cret"})
+	router := gin.New()
+	router.POST("/payments/webhook", PaymentWebhook(nil, provider))
+
+	captured := `{"id":"evt_1","type":"payment.captured","paymentId":"fake_1","amount":10}`
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      int
+	}{
+		{name: "unsigned", body: captured, want: http.StatusUnauthorized},
+		{name: "forged", body: captured, signature: strings.Repeat("0", 64), want: http.StatusUnauthorized},
+		{name: "signed for another body", body: captured, signature: provider.Sign([]byte(`{}`)), want: http.StatusUnauthorized},
+		{name: "signed but malformed", body: `{"id":`, signature: provider.Sign([]byte(`{"id":`)), want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/payments/webhook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(payments.FakeSignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
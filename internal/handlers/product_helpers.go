@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/models"
+	"backend/internal/promotions"
 )
 
 func normalizeProductDocument(raw bson.M) (models.Product, error) {
@@ -27,21 +31,26 @@ func normalizeProductDocument(raw bson.M) (models.Product, error) {
 		raw["isCampaign"] = false
 	}
 
+	// Public listings treat a missing isActive as active ($ne: false).
+	if _, ok := raw["isActive"].(bool); !ok {
+		raw["isActive"] = true
+	}
+
 	if val, ok := raw["stock"]; ok {
 		switch typed := val.(type) {
 		case int32:
-			raw["stock"] = int(typed)
+			raw["stock"] = float64(typed)
 		case int64:
-			raw["stock"] = int(typed)
+			raw["stock"] = float64(typed)
 		case float64:
-			raw["stock"] = int(typed)
-		case int:
 			raw["stock"] = typed
+		case int:
+			raw["stock"] = float64(typed)
 		default:
-			raw["stock"] = 0
+			raw["stock"] = 0.0
 		}
 	} else {
-		raw["stock"] = 0
+		raw["stock"] = 0.0
 	}
 
 	data, err := bson.Marshal(raw)
@@ -54,7 +63,7 @@ func normalizeProductDocument(raw bson.M) (models.Product, error) {
 		return models.Product{}, err
 	}
 
-	p.InStock = p.Stock > 0
+	p.InStock = p.AvailableStock() > 0
 
 	return p, nil
 }
@@ -82,3 +91,72 @@ func decodeProducts(ctx context.Context, cursor *mongo.Cursor) ([]models.Product
 
 	return products, nil
 }
+
+// applyActiveCampaigns prices products with the campaigns running now. Public
+// reads and order pricing go through it so listings and orders agree.
+func applyActiveCampaigns(ctx context.Context, db *mongo.Database, products []models.Product) error {
+	campaigns, err := promotions.ActiveCampaigns(ctx, db, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		promotions.ApplyCampaigns(&products[i], campaigns)
+	}
+	return nil
+}
+
+// attachVariants fills Variants on the grouped products in products with
+// their active variants, priced with the campaigns running now. A grouped
+// product is in stock when any of its variants is.
+func attachVariants(ctx context.Context, db *mongo.Database, products []models.Product) error {
+	parentIDs := []primitive.ObjectID{}
+	for _, p := range products {
+		if p.HasVariants {
+			parentIDs = append(parentIDs, p.ID)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	cursor, err := db.Collection("products").Find(
+		ctx,
+		bson.M{
+			"parentId":  bson.M{"$in": parentIDs},
+			"isActive":  bson.M{"$ne": false},
+			"isDeleted": bson.M{"$ne": true},
+		},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	variants, err := decodeProducts(ctx, cursor)
+	if err != nil {
+		return err
+	}
+	if err := applyActiveCampaigns(ctx, db, variants); err != nil {
+		return err
+	}
+
+	byParent := make(map[primitive.ObjectID][]models.Product, len(parentIDs))
+	for _, v := range variants {
+		byParent[*v.ParentID] = append(byParent[*v.ParentID], v)
+	}
+	for i := range products {
+		if !products[i].HasVariants {
+			continue
+		}
+		products[i].Variants = byParent[products[i].ID]
+		if products[i].Variants == nil {
+			products[i].Variants = []models.Product{}
+		}
+		products[i].InStock = false
+		for _, v := range products[i].Variants {
+			products[i].InStock = products[i].InStock || v.InStock
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+)
+
+// insertProductWithLedger inserts product and records its initial stock as a
+// manual adjustment in the same transaction.
+func insertProductWithLedger(ctx context.Context, db *mongo.Database, product models.Product, actorID string) (primitive.ObjectID, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	defer session.EndSession(ctx)
+
+	var id primitive.ObjectID
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		id, err = insertProductInTx(sessCtx, db, product, actorID)
+		return nil, err
+	})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return id, nil
+}
+
+// insertProductInTx is insertProductWithLedger for callers that already run
+// a transaction.
+func insertProductInTx(ctx context.Context, db *mongo.Database, product models.Product, actorID string) (primitive.ObjectID, error) {
+	res, err := db.Collection("products").InsertOne(ctx, product)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	id := res.InsertedID.(primitive.ObjectID)
+
+	if product.Stock == 0 {
+		return id, nil
+	}
+	return id, inventory.RecordMovement(ctx, db, models.StockMovement{
+		ProductID:  id,
+		Delta:      product.Stock,
+		StockAfter: product.Stock,
+		Reason:     models.StockReasonManualAdjustment,
+		ActorType:  models.OrderActorAdmin,
+		ActorID:    actorID,
+		Note:       "initial stock",
+	})
+}
+
+// errParentStock rejects stock on a product that groups variants; only its
+// variants are sold.
+var errParentStock = errors.New("products with variants cannot hold stock")
+
+// updateProductWithLedger applies update to a non-deleted product and, when
+// it sets a different stock value, records the difference as a manual
+// adjustment in the same transaction. It returns the number of matched
+// products. Setting stock other than zero on a product with variants fails
+// with errParentStock, and a stock or unit change that leaves a piece product
+// with fractional stock fails with errPieceStock.
+func updateProductWithLedger(ctx context.Context, db *mongo.Database, id primitive.ObjectID, update bson.M, actorID string) (int64, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.EndSession(ctx)
+
+	var matched int64
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		matched = 0
+
+		var before models.Product
+		err := db.Collection("products").FindOneAndUpdate(
+			sessCtx,
+			bson.M{
+				"_id":       id,
+				"isDeleted": bson.M{"$ne": true},
+			},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.Before),
+		).Decode(&before)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		matched = 1
+
+		set, _ := update["$set"].(bson.M)
+		stock, ok := set["stock"].(float64)
+		if ok && stock != 0 && before.HasVariants {
+			return nil, errParentStock
+		}
+		_, unitSet := set["unit"]
+		if ok || unitSet {
+			if err := checkProductStock(mergeProductStock(before, set)); err != nil {
+				return nil, err
+			}
+		}
+		if !ok || stock == before.Stock {
+			return nil, nil
+		}
+		return nil, inventory.RecordMovement(sessCtx, db, models.StockMovement{
+			ProductID:  id,
+			Delta:      models.RoundQuantity(stock - before.Stock),
+			StockAfter: stock,
+			Reason:     models.StockReasonManualAdjustment,
+			ActorType:  models.OrderActorAdmin,
+			ActorID:    actorID,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return matched, nil
+}
+
+// mergeProductStock returns product with the unit and stock of an update's
+// $set applied, so the stock can be checked against the resulting unit.
+func mergeProductStock(product models.Product, set bson.M) models.Product {
+	if unit, ok := set["unit"].(string); ok {
+		product.Unit = unit
+	}
+	if stock, ok := set["stock"].(float64); ok {
+		product.Stock = stock
+	}
+	return product
+}
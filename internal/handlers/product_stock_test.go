@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/internal/models"
+)
+
+// An update is checked against the unit the product ends up with, whether
+// it changes the stock, the unit or both.
+func TestMergedProductStockCheck(t *testing.T) {
+	cheese := models.Product{Unit: models.UnitKg, Stock: 2.5}
+	apples := models.Product{Unit: models.UnitPiece, Stock: 4}
+
+	tests := map[string]struct {
+		product models.Product
+		set     bson.M
+		ok      bool
+	}{
+		"weighed to piece with fractional stock": {cheese, bson.M{"unit": models.UnitPiece}, false},
+		"weighed to piece with whole stock":      {cheese, bson.M{"unit": models.UnitPiece, "stock": 3.0}, true},
+		"fractional stock on a piece product":    {apples, bson.M{"stock": 4.5}, false},
+		"piece to weighed with fractional stock": {apples, bson.M{"unit": models.UnitKg, "stock": 4.5}, true},
+		"whole stock on a piece product":         {apples, bson.M{"stock": 6.0}, true},
+	}
+	for name, tt := range tests {
+		err := checkProductStock(mergeProductStock(tt.product, tt.set))
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !tt.ok && !errors.Is(err, errPieceStock) {
+			t.Errorf("%s: err = %v, want errPieceStock", name, err)
+		}
+	}
+}
@@ -41,8 +41,9 @@ type productFormInput struct {
 	BarcodeSet       bool
 	Brand            string
 	BrandSet         bool
-	Stock            int
+	Stock            float64
 	StockSet         bool
+	Unit             productUnitInput
 	IsActive         bool
 	IsActiveSet      bool
 	IsCampaign       bool
@@ -118,12 +119,27 @@ func parseMultipartProductRequest(c *gin.Context, requireImage bool) (productFor
 			input.Brand = value
 			input.BrandSet = true
 		case "stock":
-			parsed, err := strconv.Atoi(value)
+			parsed, err := strconv.ParseFloat(value, 64)
 			if err != nil {
 				return productFormInput{}, err
 			}
 			input.Stock = parsed
 			input.StockSet = true
+		case "unit":
+			input.Unit.Unit = &value
+		case "quantityStep", "minQuantity", "maxQuantity":
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return productFormInput{}, err
+			}
+			switch name {
+			case "quantityStep":
+				input.Unit.QuantityStep = &parsed
+			case "minQuantity":
+				input.Unit.MinQuantity = &parsed
+			default:
+				input.Unit.MaxQuantity = &parsed
+			}
 		case "isActive":
 			parsed, err := parseBoolValue(value)
 			if err != nil {
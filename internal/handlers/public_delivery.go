@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/delivery"
+	"backend/internal/models"
+)
+
+/*
+GET /delivery/check?lat=..&lng=..
+- Konuma teslimat yapılıp yapılmadığını ve uygulanacak teslimat ücretlerini döner
+*/
+func CheckDeliveryLocation(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /delivery/check"
+		defer handlePanic(c, route)
+
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+		if latErr != nil || lngErr != nil {
+			respondWithError(c, http.StatusBadRequest, route, "lat and lng are required")
+			return
+		}
+		if err := validateCoordinates(&lat, &lng); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		zone, served, err := locateDeliveryZone(ctx, db, &lat, &lng)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if !served {
+			c.JSON(http.StatusOK, gin.H{"deliverable": false})
+			return
+		}
+
+		settings, err := delivery.LoadSettings(ctx, db)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		resp := gin.H{"deliverable": true}
+		var override *models.DeliveryPricing
+		if zone != nil {
+			override = zone.Pricing
+			resp["deliveryZone"] = models.OrderDeliveryZone{ZoneID: zone.ID, Name: zone.Name}
+		}
+		resp["pricing"] = delivery.Resolve(settings, override)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+type deliverySlotView struct {
+	delivery.Slot
+	Remaining int  `json:"remaining"`
+	Available bool `json:"available"`
+}
+
+type deliveryDayView struct {
+	Date    string             `json:"date"`
+	Holiday bool               `json:"holiday"`
+	Slots   []deliverySlotView `json:"slots"`
+}
+
+/*
+GET /delivery/slots?days=..
+- Bugünden itibaren günlere göre teslimat zamanlarını ve kalan kapasiteyi döner
+- Kapasitesi dolan veya son sipariş saati geçen zamanlar available=false döner
+*/
+func GetDeliverySlots(db *mongo.Database, loc *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /delivery/slots"
+		defer handlePanic(c, route)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		schedule, err := delivery.LoadSlotSchedule(ctx, db)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		days := delivery.DaysAhead(schedule)
+		if raw := c.Query("days"); raw != "" {
+			requested, err := strconv.Atoi(raw)
+			if err != nil || requested < 0 {
+				respondWithError(c, http.StatusBadRequest, route, "invalid days")
+				return
+			}
+			if requested < days {
+				days = requested
+			}
+		}
+
+		now := time.Now().In(loc)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+		result := make([]deliveryDayView, 0, days+1)
+		ids := []string{}
+		for i := 0; i <= days; i++ {
+			day := today.AddDate(0, 0, i)
+			date := day.Format("2006-01-02")
+			view := deliveryDayView{
+				Date:    date,
+				Holiday: delivery.IsHoliday(schedule, date),
+				Slots:   []deliverySlotView{},
+			}
+			for _, slot := range delivery.SlotsForDay(schedule, day, loc) {
+				view.Slots = append(view.Slots, deliverySlotView{Slot: slot})
+				ids = append(ids, slot.ID)
+			}
+			result = append(result, view)
+		}
+
+		booked, err := delivery.BookedCounts(ctx, db, ids)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		for i := range result {
+			for j := range result[i].Slots {
+				slot := &result[i].Slots[j]
+				slot.Remaining = slot.Capacity - booked[slot.ID]
+				if slot.Remaining < 0 {
+					slot.Remaining = 0
+				}
+				slot.Available = slot.Remaining > 0 && now.Before(slot.CutoffAt)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"required": schedule.Required && len(schedule.Windows) > 0,
+			"days":     result,
+		})
+	}
+}
@@ -14,24 +14,36 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"backend/internal/inventory"
 	"backend/internal/models"
+	"backend/internal/payments"
 )
 
 /* =========================
    REQUEST DTOs
 ========================= */
 
+// createOrderItemRequest is one order line. Variants of a grouped product
+// are ordered with the parent's productId and the variantId; a variant's own
+// id as productId works too. Name and price are kept for older clients; both
+// are ignored and re-read from the product document when the order is priced.
 type createOrderItemRequest struct {
 	ProductID string  `json:"productId" binding:"required"`
-	Name      string  `json:"name" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
-	Quantity  int     `json:"quantity" binding:"required"`
+	VariantID string  `json:"variantId"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Quantity  float64 `json:"quantity" binding:"required"`
 }
 
 type createOrderCustomerRequest struct {
-	Title  string `json:"title" binding:"required"`
-	Detail string `json:"detail" binding:"required"`
-	Note   string `json:"note"`
+	Title         string   `json:"title"`
+	Detail        string   `json:"detail"`
+	Note          string   `json:"note"`
+	City          string   `json:"city"`
+	District      string   `json:"district"`
+	Neighbourhood string   `json:"neighbourhood"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
 }
 
 type createOrderPaymentMethodRequest struct {
@@ -41,16 +53,23 @@ type createOrderPaymentMethodRequest struct {
 
 type createOrderRequest struct {
 	Items         []createOrderItemRequest        `json:"items" binding:"required"`
-	TotalPrice    float64                         `json:"totalPrice"`
-	Customer      createOrderCustomerRequest      `json:"customer" binding:"required"`
+	TotalPrice    *float64                        `json:"totalPrice"`
 	PaymentMethod createOrderPaymentMethodRequest `json:"paymentMethod" binding:"required"`
+	ReservationID string                          `json:"reservationId"`
+	CouponCode    string                          `json:"couponCode"`
+	// Customer is the free-text address; logged-in users may send the id of
+	// a saved address as AddressID instead.
+	Customer  *createOrderCustomerRequest `json:"customer"`
+	AddressID string                      `json:"addressId"`
+	// DeliverySlotID is a slot id from GET /delivery/slots.
+	DeliverySlotID string `json:"deliverySlotId"`
 }
 
 /* =========================
    CREATE ORDER
 ========================= */
 
-func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
+func CreateOrder(db *mongo.Database, jwtSecret string, dailyOrderNumbers bool, deliveryLocation *time.Location, paymentProvider payments.Provider) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const route = "POST /orders"
 		defer handlePanic(c, route)
@@ -79,97 +98,43 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 			return
 		}
 		order.UserID = userID
+		if order.PaymentMethod == "card" && paymentProvider == nil {
+			respondWithError(c, http.StatusBadRequest, route, "card payments are not available")
+			return
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		session, err := db.Client().StartSession()
+		order.Customer, err = resolveOrderCustomer(ctx, db, userID, req.AddressID, req.Customer)
 		if err != nil {
-			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			respondOrderAddressError(c, route, err)
 			return
 		}
-		defer session.EndSession(ctx)
-
-		var orderID primitive.ObjectID
-		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
-			for _, item := range order.Items {
-				var product models.Product
-				err := db.Collection("products").FindOne(
-					sessCtx,
-					bson.M{
-						"_id":       item.ProductID,
-						"isDeleted": bson.M{"$ne": true},
-					},
-				).Decode(&product)
-				if err == mongo.ErrNoDocuments {
-					return nil, productNotFoundError{ProductID: item.ProductID}
-				}
-				if err != nil {
-					return nil, err
-				}
-
-				if product.Stock < item.Quantity {
-					return nil, outOfStockError{
-						ProductID: item.ProductID,
-						Available: product.Stock,
-						Requested: item.Quantity,
-					}
-				}
-
-				filter := bson.M{
-					"_id":       item.ProductID,
-					"isDeleted": bson.M{"$ne": true},
-					"stock":     bson.M{"$gte": item.Quantity},
-				}
-				update := bson.M{"$inc": bson.M{"stock": -item.Quantity}}
 
-				res, err := db.Collection("products").UpdateOne(sessCtx, filter, update)
-				if err != nil {
-					return nil, err
-				}
-				if res.MatchedCount == 0 {
-					return nil, outOfStockError{
-						ProductID: item.ProductID,
-						Available: product.Stock,
-						Requested: item.Quantity,
-					}
-				}
-			}
-
-			res, err := db.Collection("orders").InsertOne(sessCtx, order)
+		opts := placeOrderOptions{
+			SubmittedTotal:    req.TotalPrice,
+			CouponCode:        req.CouponCode,
+			DailyOrderNumbers: dailyOrderNumbers,
+			DeliverySlotID:    req.DeliverySlotID,
+			DeliveryLocation:  deliveryLocation,
+		}
+		if req.ReservationID != "" {
+			opts.ReservationID, err = primitive.ObjectIDFromHex(req.ReservationID)
 			if err != nil {
-				return nil, err
-			}
-			if id, ok := res.InsertedID.(primitive.ObjectID); ok {
-				orderID = id
-			}
-			return nil, nil
-		})
-		if err != nil {
-			var stockErr outOfStockError
-			if errors.As(err, &stockErr) {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error":     "Stok yetersiz",
-					"productId": stockErr.ProductID.Hex(),
-					"available": stockErr.Available,
-					"requested": stockErr.Requested,
-				})
+				respondWithError(c, http.StatusBadRequest, route, "invalid reservationId")
 				return
 			}
-			var notFoundErr productNotFoundError
-			if errors.As(err, &notFoundErr) {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error":     "Ürün bulunamadı",
-					"productId": notFoundErr.ProductID.Hex(),
-				})
+			guestID, ok := reservationGuest(c, route, userID, jwtSecret)
+			if !ok {
 				return
 			}
-			respondWithError(c, http.StatusInternalServerError, route, "db error")
-			return
+			opts.ReservationGuestID = guestID
 		}
 
-		if !orderID.IsZero() {
-			order.ID = orderID
+		if err := placeOrder(ctx, db, &order, opts); err != nil {
+			respondOrderError(c, route, err)
+			return
 		}
 
 		if userID != nil {
@@ -178,37 +143,261 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 			log.Println("[ORDER] [INFO] guest order created")
 		}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"orderId": order.ID.Hex(),
-			"message": "order created",
-		})
+		resp, ok := orderCreatedResponse(c, ctx, db, paymentProvider, route, order)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
 	}
 }
 
 /* =========================
-   GET ORDERS
+   PLACE ORDER
 ========================= */
 
-func GetOrders(db *mongo.Database) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-		defer cancel()
+type placeOrderOptions struct {
+	// SubmittedTotal is the client's total, checked against the server price.
+	SubmittedTotal *float64
+	// ReservationID, when set, is consumed by the order. A guest's
+	// reservation needs the guest id of its cart token.
+	ReservationID      primitive.ObjectID
+	ReservationGuestID string
+	// CouponCode, when set, is validated and redeemed with the order.
+	CouponCode        string
+	DailyOrderNumbers bool
+	// DeliverySlotID, when set, is booked with the order; slot times are in
+	// DeliveryLocation.
+	DeliverySlotID   string
+	DeliveryLocation *time.Location
+}
+
+// placeOrder prices, stocks, numbers and inserts order in one transaction.
+// It is the single write path for new orders; on success order holds the
+// stored document.
+func placeOrder(ctx context.Context, db *mongo.Database, order *models.Order, opts placeOrderOptions) error {
+	actorType, actorID := orderActor(order.UserID)
+	order.StatusHistory = []models.OrderStatusChange{{
+		To:        order.Status,
+		ActorType: actorType,
+		ActorID:   actorID,
+		ChangedAt: order.CreatedAt,
+	}}
+
+	// The id is assigned up front so ledger entries can reference it.
+	order.ID = primitive.NewObjectID()
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// Hand the held quantities back first so this order can claim them.
+		if !opts.ReservationID.IsZero() {
+			if err := inventory.Consume(sessCtx, db, opts.ReservationID, order.UserID, opts.ReservationGuestID); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := requireDeliveryLocation(sessCtx, db, order.Customer.Latitude, order.Customer.Longitude); err != nil {
+			return nil, err
+		}
 
-		cursor, err := db.Collection("orders").Find(ctx, bson.M{})
+		priced, err := priceOrder(sessCtx, db, order, opts.CouponCode)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Orders could not be fetched"})
-			return
+			return nil, err
 		}
-		defer cursor.Close(ctx)
+		products := priced.Products
 
-		var orders []models.Order
-		if err := cursor.All(ctx, &orders); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse orders"})
-			return
+		if err := checkMinimumOrder(*order, priced.Delivery); err != nil {
+			return nil, err
+		}
+
+		if err := verifyClientTotal(opts.SubmittedTotal, *order); err != nil {
+			return nil, err
+		}
+
+		if err := bookDeliverySlot(sessCtx, db, order, opts.DeliverySlotID, opts.DeliveryLocation); err != nil {
+			return nil, err
+		}
+
+		for _, item := range order.Items {
+			product := products[item.ProductID]
+			if product.AvailableStock() < item.Quantity {
+				return nil, outOfStockError{
+					ProductID: item.ProductID,
+					Available: product.AvailableStock(),
+					Requested: item.Quantity,
+				}
+			}
+
+			// Stock held by other customers' reservations is off limits.
+			filter := bson.M{
+				"isDeleted": bson.M{"$ne": true},
+				"$expr":     inventory.AvailableAtLeast(item.Quantity),
+			}
+			movement := models.StockMovement{
+				Reason:    models.StockReasonSale,
+				ActorType: actorType,
+				ActorID:   actorID,
+				OrderID:   &order.ID,
+			}
+
+			matched, err := inventory.AdjustStock(sessCtx, db, item.ProductID, -item.Quantity, filter, movement)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				return nil, outOfStockError{
+					ProductID: item.ProductID,
+					Available: product.AvailableStock(),
+					Requested: item.Quantity,
+				}
+			}
+		}
+
+		number, err := nextOrderNumber(sessCtx, db, order.CreatedAt, opts.DailyOrderNumbers)
+		if err != nil {
+			return nil, err
 		}
+		order.Number = number
 
-		c.JSON(http.StatusOK, orders)
+		if priced.Coupon != nil {
+			if err := redeemCoupon(sessCtx, db, *priced.Coupon, *order); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = db.Collection("orders").InsertOne(sessCtx, order)
+		return nil, err
+	})
+	return err
+}
+
+// orderActor returns the status history actor for an order placed by userID,
+// or by a guest when userID is nil.
+func orderActor(userID *primitive.ObjectID) (string, string) {
+	if userID == nil {
+		return models.OrderActorGuest, ""
+	}
+	return models.OrderActorUser, userID.Hex()
+}
+
+// respondOrderError maps placeOrder errors to HTTP responses.
+func respondOrderError(c *gin.Context, route string, err error) {
+	var stockErr outOfStockError
+	if errors.As(err, &stockErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Stok yetersiz",
+			"productId": stockErr.ProductID.Hex(),
+			"available": stockErr.Available,
+			"requested": stockErr.Requested,
+		})
+		return
+	}
+	var notFoundErr productNotFoundError
+	if errors.As(err, &notFoundErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Ürün bulunamadı",
+			"productId": notFoundErr.ProductID.Hex(),
+		})
+		return
+	}
+	var variantErr variantRequiredError
+	if errors.As(err, &variantErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Ürün için varyant seçilmeli",
+			"productId": variantErr.ProductID.Hex(),
+		})
+		return
+	}
+	var quantityErr invalidQuantityError
+	if errors.As(err, &quantityErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Geçersiz miktar",
+			"productId": quantityErr.ProductID.Hex(),
+			"reason":    quantityErr.Message,
+		})
+		return
+	}
+	var unavailableErr productUnavailableError
+	if errors.As(err, &unavailableErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Ürün satışta değil",
+			"productId": unavailableErr.ProductID.Hex(),
+		})
+		return
+	}
+	if errors.Is(err, inventory.ErrReservationNotFound) || errors.Is(err, inventory.ErrReservationOwner) {
+		respondInventoryError(c, route, err)
+		return
+	}
+	var couponErr couponError
+	if errors.As(err, &couponErr) {
+		resp := gin.H{
+			"error":  "Kupon kullanılamaz",
+			"code":   couponErr.Code,
+			"reason": couponErr.Reason,
+		}
+		if couponErr.MinBasket > 0 {
+			resp["minBasket"] = couponErr.MinBasket
+		}
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	var zoneErr outsideDeliveryZoneError
+	if errors.As(err, &zoneErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Adres teslimat bölgelerimizin dışında",
+			"reason": "outside_delivery_zone",
+		})
+		return
+	}
+	var locationErr deliveryLocationRequiredError
+	if errors.As(err, &locationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Teslimat bölgesi için adresin konumu (latitude/longitude) gerekli",
+			"reason": "location_required",
+		})
+		return
+	}
+	var slotErr deliverySlotError
+	if errors.As(err, &slotErr) {
+		status := http.StatusBadRequest
+		message := "Teslimat zamanı seçilemez"
+		if slotErr.Reason == "full" {
+			status = http.StatusConflict
+			message = "Seçilen teslimat zamanı doldu"
+		}
+		c.JSON(status, gin.H{
+			"error":          message,
+			"deliverySlotId": slotErr.SlotID,
+			"reason":         slotErr.Reason,
+		})
+		return
+	}
+	var minimumErr minimumOrderError
+	if errors.As(err, &minimumErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":          "Minimum sipariş tutarının altında",
+			"minOrderAmount": minimumErr.Minimum,
+			"currentAmount":  minimumErr.Current,
+			"missingAmount":  roundPrice(minimumErr.Minimum - minimumErr.Current),
+		})
+		return
+	}
+	var mismatchErr priceMismatchError
+	if errors.As(err, &mismatchErr) {
+		log.Printf("[ORDER] [WARN] total mismatch: submitted=%.2f expected=%.2f", mismatchErr.Submitted, mismatchErr.Expected)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "Sipariş tutarı güncel fiyatlarla uyuşmuyor",
+			"expectedTotal":  mismatchErr.Expected,
+			"submittedTotal": mismatchErr.Submitted,
+		})
+		return
 	}
+	respondWithError(c, http.StatusInternalServerError, route, "db error")
 }
 
 /* =========================
@@ -224,42 +413,66 @@ func buildOrderFromRequest(req createOrderRequest) (models.Order, error) {
 		return models.Order{}, errors.New("invalid payment method")
 	}
 
-	items := make([]models.OrderItem, 0, len(req.Items))
-	var total float64
+	items, err := buildOrderItems(req.Items)
+	if err != nil {
+		return models.Order{}, err
+	}
 
-	for _, item := range req.Items {
+	// Name, price and total are filled in by priceOrderItems; the customer
+	// by resolveOrderCustomer.
+	order := models.Order{
+		Items:         items,
+		PaymentMethod: req.PaymentMethod.ID, // 🔥 sadece "card" / "cash" kaydedilir
+		PaymentStatus: models.PaymentStatusUnpaid,
+		Status:        models.OrderStatusPending,
+		CreatedAt:     time.Now(),
+	}
+
+	return order, nil
+}
+
+// buildOrderItems parses request lines, merging repeated products into one
+// line so stock and promotions see the full quantity.
+func buildOrderItems(reqItems []createOrderItemRequest) ([]models.OrderItem, error) {
+	items := make([]models.OrderItem, 0, len(reqItems))
+	index := make(map[primitive.ObjectID]int, len(reqItems))
+
+	for _, item := range reqItems {
 		productID, err := primitive.ObjectIDFromHex(item.ProductID)
 		if err != nil {
-			return models.Order{}, errors.New("invalid productId")
+			return nil, errors.New("invalid productId")
 		}
 
-		if item.Quantity <= 0 {
-			return models.Order{}, errors.New("quantity must be greater than zero")
+		// The variant is what is sold; the parent is kept to check that
+		// the variant belongs to it.
+		var parentID *primitive.ObjectID
+		if item.VariantID != "" {
+			variantID, err := primitive.ObjectIDFromHex(item.VariantID)
+			if err != nil {
+				return nil, errors.New("invalid variantId")
+			}
+			parent := productID
+			parentID = &parent
+			productID = variantID
 		}
 
-		if item.Price < 0 {
-			return models.Order{}, errors.New("price must be zero or greater")
+		if item.Quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
 		}
 
+		if i, ok := index[productID]; ok {
+			items[i].Quantity = models.RoundQuantity(items[i].Quantity + item.Quantity)
+			continue
+		}
+		index[productID] = len(items)
 		items = append(items, models.OrderItem{
 			ProductID: productID,
-			Name:      item.Name,
-			Price:     item.Price,
+			ParentID:  parentID,
 			Quantity:  item.Quantity,
 		})
-		total += item.Price * float64(item.Quantity)
 	}
 
-	order := models.Order{
-		Items:         items,
-		TotalPrice:    total,
-		Customer:      models.OrderCustomer(req.Customer),
-		PaymentMethod: req.PaymentMethod.ID, // 🔥 sadece "card" / "cash" kaydedilir
-		Status:        "pending",
-		CreatedAt:     time.Now(),
-	}
-
-	return order, nil
+	return items, nil
 }
 
 func userIDFromHeader(header, secret string) (*primitive.ObjectID, error) {
@@ -300,8 +513,8 @@ func userIDFromHeader(header, secret string) (*primitive.ObjectID, error) {
 
 type outOfStockError struct {
 	ProductID primitive.ObjectID
-	Available int
-	Requested int
+	Available float64
+	Requested float64
 }
 
 func (e outOfStockError) Error() string {
@@ -11,12 +11,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/promotions"
 )
 
 /*
 GET /products
 - Pagination OPSİYONEL
 - page + limit YOKSA → TÜM ÜRÜNLER
+- Varyantlar ana ürünün "variants" alanında gruplanır, ayrıca listelenmez
 */
 func GetProducts(db *mongo.Database) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -41,6 +45,7 @@ func GetProducts(db *mongo.Database) gin.HandlerFunc {
 		filter := bson.M{
 			"isActive":  bson.M{"$ne": false},
 			"isDeleted": bson.M{"$ne": true},
+			"parentId":  bson.M{"$exists": false},
 		}
 
 		if category := strings.TrimSpace(c.Query("category")); category != "" {
@@ -86,14 +91,26 @@ func GetProducts(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		if err := applyActiveCampaigns(ctx, db, products); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		if err := attachVariants(ctx, db, products); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
 		log.Printf("[%s] returning %d products", route, len(products))
 		c.JSON(http.StatusOK, products)
 	}
 }
 
 /*
-GET /products/campaigns
+GET /products/campaign
 - Pagination ZORUNLU
+- Şu an aktif kampanyaların kapsadığı ürünler (manuel isCampaign bayrağı kullanılmaz)
+- Varyantlar satın alınabilir ürün olarak tek tek listelenir; varyant gruplayan ana ürünler listelenmez
 - response: data + pagination
 */
 func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
@@ -104,10 +121,35 @@ func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		campaigns, err := promotions.ActiveCampaigns(ctx, db, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		scope := promotions.CampaignScope(campaigns)
+		if scope == nil {
+			c.JSON(http.StatusOK, gin.H{
+				"data": []models.Product{},
+				"pagination": gin.H{
+					"page":  page,
+					"limit": limit,
+					"total": 0,
+				},
+			})
+			return
+		}
+
 		filter := bson.M{
-			"isActive":   true,
-			"isCampaign": true,
-			"isDeleted":  bson.M{"$ne": true},
+			"isActive":    bson.M{"$ne": false},
+			"isDeleted":   bson.M{"$ne": true},
+			"hasVariants": bson.M{"$ne": true},
+		}
+		for k, v := range scope {
+			filter[k] = v
 		}
 
 		findOptions := options.Find().
@@ -115,9 +157,6 @@ func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
 			SetLimit(limit).
 			SetSort(bson.D{{Key: "createdAt", Value: -1}})
 
-		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-		defer cancel()
-
 		total, err := db.Collection("products").CountDocuments(ctx, filter)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
@@ -137,6 +176,10 @@ func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		for i := range products {
+			promotions.ApplyCampaigns(&products[i], campaigns)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"data": products,
 			"pagination": gin.H{
@@ -18,10 +18,15 @@ import (
 )
 
 type addressRequest struct {
-	Title     string `json:"title" binding:"required"`
-	Detail    string `json:"detail" binding:"required"`
-	Note      string `json:"note"`
-	IsDefault bool   `json:"isDefault"`
+	Title         string   `json:"title" binding:"required"`
+	Detail        string   `json:"detail" binding:"required"`
+	Note          string   `json:"note"`
+	City          string   `json:"city"`
+	District      string   `json:"district"`
+	Neighbourhood string   `json:"neighbourhood"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+	IsDefault     bool     `json:"isDefault"`
 }
 
 func GetMe(db *mongo.Database) gin.HandlerFunc {
@@ -94,6 +99,10 @@ func CreateUserAddress(db *mongo.Database) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
 			return
 		}
+		if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
@@ -119,11 +128,16 @@ func CreateUserAddress(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		address := models.Address{
-			ID:        addressID,
-			Title:     strings.TrimSpace(req.Title),
-			Detail:    strings.TrimSpace(req.Detail),
-			Note:      strings.TrimSpace(req.Note),
-			IsDefault: req.IsDefault,
+			ID:            addressID,
+			Title:         strings.TrimSpace(req.Title),
+			Detail:        strings.TrimSpace(req.Detail),
+			Note:          strings.TrimSpace(req.Note),
+			City:          strings.TrimSpace(req.City),
+			District:      strings.TrimSpace(req.District),
+			Neighbourhood: strings.TrimSpace(req.Neighbourhood),
+			Latitude:      req.Latitude,
+			Longitude:     req.Longitude,
+			IsDefault:     req.IsDefault,
 		}
 
 		user.Addresses = append(user.Addresses, address)
@@ -142,7 +156,7 @@ func CreateUserAddress(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		log.Println("[ADDRESS] [INFO] address created:", address.ID)
-		c.JSON(http.StatusCreated, gin.H{"address": address})
+		c.JSON(http.StatusCreated, addressZoneResponse(ctx, db, address))
 	}
 }
 
@@ -179,6 +193,10 @@ func UpdateUserAddress(db *mongo.Database) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
 			return
 		}
+		if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		addressID := strings.TrimSpace(c.Param("id"))
 		if addressID == "" {
@@ -217,6 +235,11 @@ func UpdateUserAddress(db *mongo.Database) gin.HandlerFunc {
 		user.Addresses[index].Title = strings.TrimSpace(req.Title)
 		user.Addresses[index].Detail = strings.TrimSpace(req.Detail)
 		user.Addresses[index].Note = strings.TrimSpace(req.Note)
+		user.Addresses[index].City = strings.TrimSpace(req.City)
+		user.Addresses[index].District = strings.TrimSpace(req.District)
+		user.Addresses[index].Neighbourhood = strings.TrimSpace(req.Neighbourhood)
+		user.Addresses[index].Latitude = req.Latitude
+		user.Addresses[index].Longitude = req.Longitude
 		user.Addresses[index].IsDefault = req.IsDefault
 		user.UpdatedAt = time.Now()
 
@@ -233,7 +256,7 @@ func UpdateUserAddress(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		log.Println("[ADDRESS] [INFO] address updated:", addressID)
-		c.JSON(http.StatusOK, gin.H{"address": user.Addresses[index]})
+		c.JSON(http.StatusOK, addressZoneResponse(ctx, db, user.Addresses[index]))
 	}
 }
 
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+type cartCheckoutRequest struct {
+	TotalPrice    *float64                        `json:"totalPrice"`
+	PaymentMethod createOrderPaymentMethodRequest `json:"paymentMethod" binding:"required"`
+	ReservationID string                          `json:"reservationId"`
+	CouponCode    string                          `json:"couponCode"`
+	// Customer is the free-text address; AddressID picks a saved one.
+	Customer  *createOrderCustomerRequest `json:"customer"`
+	AddressID string                      `json:"addressId"`
+	// DeliverySlotID is a slot id from GET /delivery/slots.
+	DeliverySlotID string `json:"deliverySlotId"`
+}
+
+func userCartOwner(c *gin.Context, _ bool) (cartOwner, bool) {
+	userIDValue, ok := c.Get("userId")
+	if !ok {
+		log.Println("[CART] [ERROR] userId missing in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return cartOwner{}, false
+	}
+	return userCartOwnerFor(userIDValue.(primitive.ObjectID)), true
+}
+
+/*
+GET /user/cart
+- Sepet her okumada products koleksiyonundan yeniden fiyatlanır
+- Pasif, silinmiş veya stoğu yetmeyen ürünler status ile işaretlenir
+*/
+func GetUserCart(db *mongo.Database) gin.HandlerFunc {
+	return getCartHandler(db, userCartOwner)
+}
+
+/*
+POST /user/cart/items
+- Ürün sepette varsa adet artırılır
+*/
+func AddUserCartItem(db *mongo.Database) gin.HandlerFunc {
+	return addCartItemHandler(db, userCartOwner)
+}
+
+/*
+PUT /user/cart/items/:productId
+- quantity 0 gönderilirse ürün sepetten çıkarılır
+*/
+func SetUserCartItemQuantity(db *mongo.Database) gin.HandlerFunc {
+	return setCartItemQuantityHandler(db, userCartOwner)
+}
+
+/*
+DELETE /user/cart/items/:productId
+*/
+func RemoveUserCartItem(db *mongo.Database) gin.HandlerFunc {
+	return removeCartItemHandler(db, userCartOwner)
+}
+
+/*
+DELETE /user/cart
+*/
+func ClearUserCart(db *mongo.Database) gin.HandlerFunc {
+	return clearCartHandler(db, userCartOwner)
+}
+
+/*
+POST /user/cart/checkout
+- Sepetteki ürünlerden POST /orders ile aynı transaction akışıyla sipariş oluşturur
+- Sepette satın alınamayan ürün varsa 409 ve güncel sepet döner
+- Başarılı siparişten sonra sepet boşaltılır
+*/
+func CheckoutUserCart(db *mongo.Database, dailyOrderNumbers bool, deliveryLocation *time.Location, paymentProvider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /user/cart/checkout"
+		defer handlePanic(c, route)
+
+		owner, ok := userCartOwner(c, false)
+		if !ok {
+			return
+		}
+		userID := c.MustGet("userId").(primitive.ObjectID)
+
+		var req cartCheckoutRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid request body")
+			return
+		}
+		if req.PaymentMethod.ID == "card" && paymentProvider == nil {
+			respondWithError(c, http.StatusBadRequest, route, "card payments are not available")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cart, err := loadCart(ctx, db, owner)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if len(cart.Items) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "cart is empty")
+			return
+		}
+
+		// The user's own hold is handed back by placeOrder, so it counts as
+		// available for the pre-check too.
+		var reservationID primitive.ObjectID
+		held := map[primitive.ObjectID]float64{}
+		if req.ReservationID != "" {
+			reservationID, err = primitive.ObjectIDFromHex(req.ReservationID)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "invalid reservationId")
+				return
+			}
+			if held, err = inventory.Held(ctx, db, reservationID, &userID, ""); err != nil {
+				respondOrderError(c, route, err)
+				return
+			}
+		}
+
+		view, err := buildCartView(ctx, db, cart, held)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if view.HasIssues {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Sepette satın alınamayan ürünler var",
+				"cart":  view,
+			})
+			return
+		}
+
+		orderReq := createOrderRequest{
+			Items:         make([]createOrderItemRequest, 0, len(cart.Items)),
+			PaymentMethod: req.PaymentMethod,
+		}
+		for _, item := range cart.Items {
+			orderReq.Items = append(orderReq.Items, createOrderItemRequest{
+				ProductID: item.ProductID.Hex(),
+				Quantity:  item.Quantity,
+			})
+		}
+
+		order, err := buildOrderFromRequest(orderReq)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		order.UserID = &userID
+
+		order.Customer, err = resolveOrderCustomer(ctx, db, &userID, req.AddressID, req.Customer)
+		if err != nil {
+			respondOrderAddressError(c, route, err)
+			return
+		}
+
+		opts := placeOrderOptions{
+			SubmittedTotal:    req.TotalPrice,
+			CouponCode:        req.CouponCode,
+			DailyOrderNumbers: dailyOrderNumbers,
+			DeliverySlotID:    req.DeliverySlotID,
+			DeliveryLocation:  deliveryLocation,
+			ReservationID:     reservationID,
+		}
+		if err := placeOrder(ctx, db, &order, opts); err != nil {
+			respondOrderError(c, route, err)
+			return
+		}
+
+		// The order is committed; a failed clear only leaves a stale cart.
+		if _, err := saveCartItems(ctx, db, owner, []models.CartItem{}); err != nil {
+			log.Println("[CART] [ERROR] clear cart after checkout failed:", err)
+		}
+
+		log.Println("[ORDER] [INFO] cart checkout for user:", userID.Hex())
+		resp, ok := orderCreatedResponse(c, ctx, db, paymentProvider, route, order)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	}
+}
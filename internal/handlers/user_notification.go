@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+/*
+GET /user/notifications
+- Giriş yapan kullanıcının bildirimleri, en yeni önce
+- ?unread=true ile sadece okunmamışlar
+- response: data + unreadCount + pagination
+*/
+func GetUserNotifications(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDValue, ok := c.Get("userId")
+		if !ok {
+			log.Println("[NOTIFICATION] [ERROR] userId missing in context")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		userID := userIDValue.(primitive.ObjectID)
+
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination params"})
+			return
+		}
+
+		filter := bson.M{"userId": userID}
+		unreadFilter := bson.M{"userId": userID, "readAt": bson.M{"$exists": false}}
+		if c.Query("unread") == "true" {
+			filter = unreadFilter
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		collection := db.Collection("notifications")
+		total, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		unread, err := collection.CountDocuments(ctx, unreadFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		opts := options.Find().
+			SetSkip((page - 1) * limit).
+			SetLimit(limit).
+			SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+		cursor, err := collection.Find(ctx, filter, opts)
+		if err != nil {
+			log.Println("[NOTIFICATION] [ERROR] list notifications failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		notifications := make([]models.Notification, 0)
+		if err := cursor.All(ctx, &notifications); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":        notifications,
+			"unreadCount": unread,
+			"pagination":  paginationResponse(page, limit, total),
+		})
+	}
+}
+
+/*
+POST /user/notifications/:id/read
+- Bildirimi okundu olarak işaretler (tekrar çağrılması zararsızdır)
+*/
+func MarkUserNotificationRead(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDValue, ok := c.Get("userId")
+		if !ok {
+			log.Println("[NOTIFICATION] [ERROR] userId missing in context")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		userID := userIDValue.(primitive.ObjectID)
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		collection := db.Collection("notifications")
+		filter := bson.M{"_id": id, "userId": userID}
+		_, err = collection.UpdateOne(ctx,
+			bson.M{"_id": id, "userId": userID, "readAt": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"readAt": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		var notification models.Notification
+		err = collection.FindOne(ctx, filter).Decode(&notification)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, notification)
+	}
+}
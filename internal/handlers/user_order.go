@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/payments"
+)
+
+/*
+GET /user/orders
+- Sadece giriş yapan kullanıcının siparişleri
+- ?status=pending,preparing ile filtrelenebilir
+- ?number=100042 ile sipariş numarasına göre aranabilir
+- response: data + pagination
+*/
+func GetUserOrders(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDValue, ok := c.Get("userId")
+		if !ok {
+			log.Println("[ORDER] [ERROR] userId missing in context")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		userID := userIDValue.(primitive.ObjectID)
+
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination params"})
+			return
+		}
+
+		filter := bson.M{
+			"userId":    userID,
+			"isDeleted": bson.M{"$ne": true},
+		}
+
+		status, err := parseStatusFilter(c.Query("status"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if status != nil {
+			filter["status"] = status
+		}
+
+		if number := strings.TrimPrefix(strings.TrimSpace(c.Query("number")), "#"); number != "" {
+			filter["number"] = number
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		orders, total, err := findOrdersPage(ctx, db, filter, page, limit)
+		if err != nil {
+			log.Println("[ORDER] [ERROR] list user orders failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":       orders,
+			"pagination": paginationResponse(page, limit, total),
+		})
+	}
+}
+
+/*
+POST /user/orders/:id/cancel
+- Sadece pending/confirmed durumundaki siparişler iptal edilebilir
+- Stoklar aynı transaction içinde geri yüklenir
+*/
+func CancelUserOrder(db *mongo.Database, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDValue, ok := c.Get("userId")
+		if !ok {
+			log.Println("[ORDER] [ERROR] userId missing in context")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		userID := userIDValue.(primitive.ObjectID)
+
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req OrderCancelRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		updated, err := transitionOrder(
+			ctx,
+			db,
+			bson.M{"_id": orderID, "userId": userID, "isDeleted": bson.M{"$ne": true}},
+			models.OrderStatusChange{
+				To:        models.OrderStatusCancelled,
+				ActorType: models.OrderActorUser,
+				ActorID:   userID.Hex(),
+				Note:      strings.TrimSpace(req.Reason),
+			},
+			models.OrderStatusPending,
+			models.OrderStatusConfirmed,
+		)
+		if err != nil {
+			respondOrderTransitionError(c, err)
+			return
+		}
+		updated = completePendingRefunds(ctx, db, provider, updated)
+
+		log.Println("[ORDER] [INFO] order cancelled by user:", orderID.Hex())
+		c.JSON(http.StatusOK, updated)
+	}
+}
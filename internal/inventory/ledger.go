@@ -0,0 +1,54 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// AdjustStock increments the product's stock by delta and appends movement to
+// the stock_movements ledger. filter may add conditions (e.g. availability)
+// on top of the product id; when nothing matches no movement is written and
+// false is returned. Call it inside a transaction so the ledger and the stock
+// can never disagree.
+func AdjustStock(ctx context.Context, db *mongo.Database, productID primitive.ObjectID, delta float64, filter bson.M, movement models.StockMovement) (bool, error) {
+	query := bson.M{"_id": productID}
+	for k, v := range filter {
+		query[k] = v
+	}
+
+	var product models.Product
+	err := db.Collection("products").FindOneAndUpdate(
+		ctx,
+		query,
+		bson.M{"$inc": bson.M{"stock": delta}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	movement.ProductID = productID
+	movement.Delta = delta
+	movement.StockAfter = models.RoundQuantity(product.Stock)
+	return true, RecordMovement(ctx, db, movement)
+}
+
+// RecordMovement appends movement to the ledger. Use it when the stock was
+// written some other way, e.g. set directly by an admin.
+func RecordMovement(ctx context.Context, db *mongo.Database, movement models.StockMovement) error {
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+	_, err := db.Collection("stock_movements").InsertOne(ctx, movement)
+	return err
+}
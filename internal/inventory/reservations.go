@@ -0,0 +1,336 @@
+// Package inventory holds stock operations shared by request handlers and
+// background jobs.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found or expired")
+	ErrReservationOwner    = errors.New("reservation belongs to another user")
+)
+
+// InsufficientStockError reports a product whose available stock (stock minus
+// active reservations) cannot cover the requested quantity.
+type InsufficientStockError struct {
+	ProductID primitive.ObjectID
+	Available float64
+	Requested float64
+}
+
+func (e InsufficientStockError) Error() string {
+	return "product out of stock"
+}
+
+// ProductNotFoundError reports a missing or soft-deleted product.
+type ProductNotFoundError struct {
+	ProductID primitive.ObjectID
+}
+
+func (e ProductNotFoundError) Error() string {
+	return "product not found"
+}
+
+// AvailableAtLeast matches products whose stock minus reserved quantity is at
+// least quantity. Weighed stock is stored as doubles, so the comparison
+// allows for float error below the quantity precision.
+func AvailableAtLeast(quantity float64) bson.M {
+	return bson.M{"$gte": bson.A{
+		bson.M{"$subtract": bson.A{"$stock", bson.M{"$ifNull": bson.A{"$reserved", 0}}}},
+		quantity - quantityTolerance,
+	}}
+}
+
+// quantityTolerance is half of the smallest quantity step RoundQuantity
+// keeps.
+const quantityTolerance = 0.0005
+
+// MergeReservationItems sums quantities of repeated products.
+func MergeReservationItems(items []models.StockReservationItem) []models.StockReservationItem {
+	index := make(map[primitive.ObjectID]int, len(items))
+	merged := make([]models.StockReservationItem, 0, len(items))
+	for _, item := range items {
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity = models.RoundQuantity(merged[i].Quantity + item.Quantity)
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// Reserve holds items for ttl. A logged-in user, or a guest identified by
+// guestID, keeps a single hold: their previous active reservations are
+// released in the same transaction.
+func Reserve(ctx context.Context, db *mongo.Database, userID *primitive.ObjectID, guestID string, items []models.StockReservationItem, ttl time.Duration) (models.StockReservation, error) {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+	defer session.EndSession(ctx)
+
+	items = MergeReservationItems(items)
+
+	var reservation models.StockReservation
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		previousFilter := bson.M{"status": models.ReservationActive}
+		if userID != nil {
+			previousFilter["userId"] = userID
+		} else {
+			previousFilter["guestId"] = guestID
+		}
+		if userID != nil || guestID != "" {
+			cursor, err := db.Collection("stock_reservations").Find(sessCtx, previousFilter)
+			if err != nil {
+				return nil, err
+			}
+			var previous []models.StockReservation
+			if err := cursor.All(sessCtx, &previous); err != nil {
+				return nil, err
+			}
+			for _, p := range previous {
+				if err := finishReservation(sessCtx, db, p, models.ReservationReleased); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for _, item := range items {
+			res, err := db.Collection("products").UpdateOne(
+				sessCtx,
+				bson.M{
+					"_id":       item.ProductID,
+					"isDeleted": bson.M{"$ne": true},
+					"isActive":  bson.M{"$ne": false},
+					"$expr":     AvailableAtLeast(item.Quantity),
+				},
+				bson.M{"$inc": bson.M{"reserved": item.Quantity}},
+			)
+			if err != nil {
+				return nil, err
+			}
+			if res.MatchedCount == 0 {
+				return nil, stockError(sessCtx, db, item)
+			}
+		}
+
+		now := time.Now()
+		reservation = models.StockReservation{
+			UserID:    userID,
+			GuestID:   guestID,
+			Items:     items,
+			Status:    models.ReservationActive,
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		res, err := db.Collection("stock_reservations").InsertOne(sessCtx, reservation)
+		if err != nil {
+			return nil, err
+		}
+		reservation.ID = res.InsertedID.(primitive.ObjectID)
+		return nil, nil
+	})
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// Consume marks an active, unexpired reservation as used by an order and
+// gives its held quantities back to the available pool, so the order's own
+// stock decrement can claim them. It must run inside the order transaction.
+// Guests pass the guest id of their cart token.
+func Consume(ctx context.Context, db *mongo.Database, reservationID primitive.ObjectID, userID *primitive.ObjectID, guestID string) error {
+	reservation, err := findActive(ctx, db, reservationID, userID, guestID)
+	if err != nil {
+		return err
+	}
+	if reservation.ExpiresAt.Before(time.Now()) {
+		return ErrReservationNotFound
+	}
+	return finishReservation(ctx, db, reservation, models.ReservationConsumed)
+}
+
+// Held returns what an active, unexpired reservation holds per product
+// without changing it, so a quote can count the caller's own hold as
+// available the way Consume does for the order.
+func Held(ctx context.Context, db *mongo.Database, reservationID primitive.ObjectID, userID *primitive.ObjectID, guestID string) (map[primitive.ObjectID]float64, error) {
+	reservation, err := findActive(ctx, db, reservationID, userID, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.ExpiresAt.Before(time.Now()) {
+		return nil, ErrReservationNotFound
+	}
+
+	held := make(map[primitive.ObjectID]float64, len(reservation.Items))
+	for _, item := range reservation.Items {
+		held[item.ProductID] += item.Quantity
+	}
+	return held, nil
+}
+
+// Release cancels an active reservation early.
+func Release(ctx context.Context, db *mongo.Database, reservationID primitive.ObjectID, userID *primitive.ObjectID, guestID string) error {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		reservation, err := findActive(sessCtx, db, reservationID, userID, guestID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, finishReservation(sessCtx, db, reservation, models.ReservationReleased)
+	})
+	return err
+}
+
+// ReleaseExpired releases every active reservation past its expiry and
+// returns how many were released.
+func ReleaseExpired(ctx context.Context, db *mongo.Database) (int, error) {
+	cursor, err := db.Collection("stock_reservations").Find(
+		ctx,
+		bson.M{
+			"status":    models.ReservationActive,
+			"expiresAt": bson.M{"$lt": time.Now()},
+		},
+		options.Find().SetLimit(500),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []models.StockReservation
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.EndSession(ctx)
+
+	released := 0
+	for _, reservation := range expired {
+		_, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			return nil, finishReservation(sessCtx, db, reservation, models.ReservationReleased)
+		})
+		if errors.Is(err, ErrReservationNotFound) {
+			// Consumed or released concurrently.
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// findActive loads an active reservation that belongs to the caller; see
+// checkOwner.
+func findActive(ctx context.Context, db *mongo.Database, reservationID primitive.ObjectID, userID *primitive.ObjectID, guestID string) (models.StockReservation, error) {
+	var reservation models.StockReservation
+	err := db.Collection("stock_reservations").FindOne(ctx, bson.M{
+		"_id":    reservationID,
+		"status": models.ReservationActive,
+	}).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return models.StockReservation{}, ErrReservationNotFound
+	}
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+
+	if err := checkOwner(reservation, userID, guestID); err != nil {
+		return models.StockReservation{}, err
+	}
+	return reservation, nil
+}
+
+// checkOwner lets a user's reservation be used only by that user and a
+// guest's only with the guest id of the cart token it was made with.
+func checkOwner(reservation models.StockReservation, userID *primitive.ObjectID, guestID string) error {
+	if reservation.UserID != nil {
+		if userID == nil || *reservation.UserID != *userID {
+			return ErrReservationOwner
+		}
+		return nil
+	}
+	if reservation.GuestID != "" && reservation.GuestID != guestID {
+		return ErrReservationOwner
+	}
+	return nil
+}
+
+// finishReservation moves an active reservation to status and removes its
+// quantities from the products' reserved counters.
+func finishReservation(ctx context.Context, db *mongo.Database, reservation models.StockReservation, status string) error {
+	res, err := db.Collection("stock_reservations").UpdateOne(
+		ctx,
+		bson.M{"_id": reservation.ID, "status": models.ReservationActive},
+		bson.M{"$set": bson.M{"status": status, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrReservationNotFound
+	}
+
+	for _, item := range reservation.Items {
+		// Never let reserved drop below zero, e.g. after a manual fix-up.
+		_, err := db.Collection("products").UpdateOne(
+			ctx,
+			bson.M{"_id": item.ProductID},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"reserved": bson.M{"$max": bson.A{
+					0,
+					bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$reserved", 0}}, item.Quantity}},
+				}},
+			}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stockError(ctx context.Context, db *mongo.Database, item models.StockReservationItem) error {
+	var product models.Product
+	err := db.Collection("products").FindOne(ctx, bson.M{
+		"_id":       item.ProductID,
+		"isDeleted": bson.M{"$ne": true},
+		"isActive":  bson.M{"$ne": false},
+	}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return ProductNotFoundError{ProductID: item.ProductID}
+	}
+	if err != nil {
+		return err
+	}
+	return InsufficientStockError{
+		ProductID: item.ProductID,
+		Available: product.AvailableStock(),
+		Requested: item.Quantity,
+	}
+}
@@ -0,0 +1,47 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestCheckOwner(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	userHold := models.StockReservation{UserID: &alice}
+	guestHold := models.StockReservation{GuestID: "guest-a"}
+	// Holds made before guests had to send a cart token carry no guest id.
+	legacyHold := models.StockReservation{}
+
+	tests := []struct {
+		name        string
+		reservation models.StockReservation
+		userID      *primitive.ObjectID
+		guestID     string
+		wantErr     bool
+	}{
+		{name: "own user hold", reservation: userHold, userID: &alice},
+		{name: "another user's hold", reservation: userHold, userID: &bob, wantErr: true},
+		{name: "user hold used by a guest", reservation: userHold, guestID: "guest-a", wantErr: true},
+		{name: "own guest hold", reservation: guestHold, guestID: "guest-a"},
+		{name: "foreign guest id", reservation: guestHold, guestID: "guest-b", wantErr: true},
+		{name: "guest hold without cart token", reservation: guestHold, wantErr: true},
+		{name: "guest hold used by a user", reservation: guestHold, userID: &alice, wantErr: true},
+		{name: "legacy guest hold", reservation: legacyHold, guestID: "guest-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOwner(tt.reservation, tt.userID, tt.guestID)
+			if tt.wantErr && !errors.Is(err, ErrReservationOwner) {
+				t.Errorf("err = %v, want ErrReservationOwner", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+const orderArchiveBatchSize = 200
+
+// StartOrderArchiver periodically moves orders older than maxAge into the
+// orders_archive collection. Only finished (delivered, cancelled, rejected)
+// or soft-deleted orders are moved; open orders stay where they are.
+func StartOrderArchiver(db *mongo.Database, maxAge, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			moved, err := ArchiveOrders(context.Background(), db, time.Now().Add(-maxAge))
+			if err != nil {
+				log.Println("[ARCHIVE] [ERROR] order archive run failed:", err)
+			} else if moved > 0 {
+				log.Println("[ARCHIVE] [INFO] orders archived:", moved)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ArchiveOrders moves eligible orders created before cutoff in batches, each
+// batch inside its own transaction, and returns how many were moved.
+func ArchiveOrders(ctx context.Context, db *mongo.Database, cutoff time.Time) (int, error) {
+	filter := bson.M{
+		"createdAt": bson.M{"$lt": cutoff},
+		"$or": bson.A{
+			bson.M{"isDeleted": true},
+			bson.M{"status": bson.M{"$in": []string{
+				models.OrderStatusDelivered,
+				models.OrderStatusCancelled,
+				models.OrderStatusRejected,
+			}}},
+		},
+	}
+
+	total := 0
+	for {
+		moved, err := archiveOrderBatch(ctx, db, filter)
+		if err != nil {
+			return total, err
+		}
+		total += moved
+		if moved < orderArchiveBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func archiveOrderBatch(ctx context.Context, db *mongo.Database, filter bson.M) (int, error) {
+	batchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.EndSession(batchCtx)
+
+	moved := 0
+	_, err = session.WithTransaction(batchCtx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		moved = 0
+
+		cursor, err := db.Collection("orders").Find(
+			sessCtx,
+			filter,
+			options.Find().SetLimit(orderArchiveBatchSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var orders []bson.M
+		if err := cursor.All(sessCtx, &orders); err != nil {
+			return nil, err
+		}
+		if len(orders) == 0 {
+			return nil, nil
+		}
+
+		now := time.Now()
+		docs := make([]interface{}, 0, len(orders))
+		ids := make([]interface{}, 0, len(orders))
+		for _, order := range orders {
+			order["archivedAt"] = now
+			docs = append(docs, order)
+			ids = append(ids, order["_id"])
+		}
+
+		if _, err := db.Collection("orders_archive").InsertMany(sessCtx, docs); err != nil {
+			return nil, err
+		}
+		if _, err := db.Collection("orders").DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return nil, err
+		}
+
+		moved = len(orders)
+		return nil, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartPaymentCaptureRetrier periodically runs retry, which captures card
+// payments whose earlier capture failed (see handlers.RetryPaymentCaptures).
+func StartPaymentCaptureRetrier(interval time.Duration, retry func(ctx context.Context) (int, error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			captured, err := retry(ctx)
+			cancel()
+			if err != nil {
+				log.Println("[PAYMENT] [ERROR] capture retry run failed:", err)
+				continue
+			}
+			if captured > 0 {
+				log.Println("[PAYMENT] [INFO] payments captured on retry:", captured)
+			}
+		}
+	}()
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/inventory"
+)
+
+// StartReservationSweeper periodically releases expired stock reservations so
+// their quantities become available again.
+func StartReservationSweeper(db *mongo.Database, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			released, err := inventory.ReleaseExpired(ctx, db)
+			cancel()
+			if err != nil {
+				log.Println("[RESERVATION] [ERROR] sweep failed:", err)
+				continue
+			}
+			if released > 0 {
+				log.Println("[RESERVATION] [INFO] expired reservations released:", released)
+			}
+		}
+	}()
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Campaign discount types. A percent campaign takes Value percent off the
+// list price; a fixed_price campaign sells matching products at Value.
+const (
+	CampaignDiscountPercent    = "percent"
+	CampaignDiscountFixedPrice = "fixed_price"
+)
+
+// Campaign is a scheduled price discount for a set of products and/or
+// categories. It applies only between StartsAt and EndsAt while IsActive.
+type Campaign struct {
+	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name         string               `bson:"name" json:"name"`
+	Description  string               `bson:"description,omitempty" json:"description,omitempty"`
+	DiscountType string               `bson:"discountType" json:"discountType"`
+	Value        float64              `bson:"value" json:"value"`
+	ProductIDs   []primitive.ObjectID `bson:"productIds,omitempty" json:"productIds,omitempty"`
+	Categories   []string             `bson:"categories,omitempty" json:"categories,omitempty"`
+	StartsAt     time.Time            `bson:"startsAt" json:"startsAt"`
+	EndsAt       time.Time            `bson:"endsAt" json:"endsAt"`
+	IsActive     bool                 `bson:"isActive" json:"isActive"`
+	CreatedAt    time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time            `bson:"updatedAt" json:"updatedAt"`
+}
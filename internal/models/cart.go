@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CartItem is a product line in a cart. Prices are not stored; carts are
+// re-priced from products on every read.
+type CartItem struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	AddedAt   time.Time          `bson:"addedAt" json:"addedAt"`
+}
+
+// Cart is a server-side shopping cart owned either by a user or by a guest
+// identified through a signed cart token. Guest carts carry ExpiresAt and are
+// removed by a TTL index once abandoned.
+type Cart struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    *primitive.ObjectID `bson:"userId,omitempty" json:"userId,omitempty"`
+	GuestID   string              `bson:"guestId,omitempty" json:"-"`
+	Items     []CartItem          `bson:"items" json:"items"`
+	ExpiresAt *time.Time          `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
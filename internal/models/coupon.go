@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Coupon discount types.
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
+
+// Coupon is a discount code redeemed at checkout. ProductIDs and Categories
+// scope the discount to matching lines; both empty means the whole basket.
+// Zero limits and MaxDiscount mean unlimited.
+type Coupon struct {
+	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Code         string               `bson:"code" json:"code"`
+	Description  string               `bson:"description,omitempty" json:"description,omitempty"`
+	Type         string               `bson:"type" json:"type"`
+	Value        float64              `bson:"value" json:"value"`
+	MaxDiscount  float64              `bson:"maxDiscount,omitempty" json:"maxDiscount,omitempty"`
+	MinBasket    float64              `bson:"minBasket,omitempty" json:"minBasket,omitempty"`
+	ProductIDs   []primitive.ObjectID `bson:"productIds,omitempty" json:"productIds,omitempty"`
+	Categories   []string             `bson:"categories,omitempty" json:"categories,omitempty"`
+	UsageLimit   int                  `bson:"usageLimit" json:"usageLimit"`
+	PerUserLimit int                  `bson:"perUserLimit" json:"perUserLimit"`
+	UsedCount    int                  `bson:"usedCount" json:"usedCount"`
+	StartsAt     *time.Time           `bson:"startsAt,omitempty" json:"startsAt,omitempty"`
+	EndsAt       *time.Time           `bson:"endsAt,omitempty" json:"endsAt,omitempty"`
+	IsActive     bool                 `bson:"isActive" json:"isActive"`
+	CreatedAt    time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time            `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CouponRedemption records one use of a coupon by an order.
+type CouponRedemption struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	CouponID  primitive.ObjectID  `bson:"couponId" json:"couponId"`
+	Code      string              `bson:"code" json:"code"`
+	UserID    *primitive.ObjectID `bson:"userId,omitempty" json:"userId,omitempty"`
+	OrderID   primitive.ObjectID  `bson:"orderId" json:"orderId"`
+	Discount  float64             `bson:"discount" json:"discount"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
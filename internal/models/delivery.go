@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DeliveryPricing is how delivery is charged for a basket. Amounts are
+// compared with the basket after discounts; zero disables a rule.
+type DeliveryPricing struct {
+	MinOrderAmount        float64 `bson:"minOrderAmount" json:"minOrderAmount"`
+	Fee                   float64 `bson:"fee" json:"fee"`
+	FreeDeliveryThreshold float64 `bson:"freeDeliveryThreshold" json:"freeDeliveryThreshold"`
+}
+
+// DeliverySettings is the single delivery pricing document in settings.
+type DeliverySettings struct {
+	ID              string `bson:"_id" json:"-"`
+	DeliveryPricing `bson:",inline"`
+	UpdatedAt       time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	UpdatedBy       string    `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// DeliverySlotWindow is a recurring delivery window such as 18:00–20:00.
+// Start and End are "HH:MM" in the delivery time zone. Booking closes
+// CutoffMinutes before Start.
+type DeliverySlotWindow struct {
+	// Weekdays limits the window to these days (0 = Sunday); empty means
+	// every day.
+	Weekdays      []int  `bson:"weekdays,omitempty" json:"weekdays,omitempty"`
+	Start         string `bson:"start" json:"start"`
+	End           string `bson:"end" json:"end"`
+	Capacity      int    `bson:"capacity" json:"capacity"`
+	CutoffMinutes int    `bson:"cutoffMinutes" json:"cutoffMinutes"`
+}
+
+// DeliverySlotSchedule is the single slot schedule document in settings.
+// Holidays are "YYYY-MM-DD" dates without deliveries. When Required is set
+// every order must book a slot.
+type DeliverySlotSchedule struct {
+	ID        string               `bson:"_id" json:"-"`
+	Windows   []DeliverySlotWindow `bson:"windows" json:"windows"`
+	Holidays  []string             `bson:"holidays" json:"holidays"`
+	DaysAhead int                  `bson:"daysAhead" json:"daysAhead"`
+	Required  bool                 `bson:"required" json:"required"`
+	UpdatedAt time.Time            `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	UpdatedBy string               `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+}
+
+// DeliverySlotBooking counts the orders booked into one slot. The id is the
+// slot id, e.g. "2026-10-18_18:00-20:00".
+type DeliverySlotBooking struct {
+	ID        string    `bson:"_id" json:"id"`
+	StartsAt  time.Time `bson:"startsAt" json:"startsAt"`
+	Booked    int       `bson:"booked" json:"booked"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// OrderDeliverySlot snapshots the slot an order was booked into.
+type OrderDeliverySlot struct {
+	ID       string    `bson:"id" json:"id"`
+	StartsAt time.Time `bson:"startsAt" json:"startsAt"`
+	EndsAt   time.Time `bson:"endsAt" json:"endsAt"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GeoJSONGeometry is a GeoJSON Polygon or MultiPolygon as stored by Mongo.
+type GeoJSONGeometry struct {
+	Type        string      `bson:"type" json:"type"`
+	Coordinates interface{} `bson:"coordinates" json:"coordinates"`
+}
+
+// DeliveryZone is an area the market delivers to. Pricing, when set,
+// replaces the default delivery settings for orders inside the zone.
+type DeliveryZone struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Area      GeoJSONGeometry    `bson:"area" json:"area"`
+	Pricing   *DeliveryPricing   `bson:"pricing,omitempty" json:"pricing,omitempty"`
+	IsActive  bool               `bson:"isActive" json:"isActive"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// OrderDeliveryZone snapshots the zone an order was delivered to.
+type OrderDeliveryZone struct {
+	ZoneID primitive.ObjectID `bson:"zoneId" json:"zoneId"`
+	Name   string             `bson:"name" json:"name"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Idempotency record states.
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord stores the outcome of a request made with an
+// Idempotency-Key header so retries can be answered without re-running it.
+type IdempotencyRecord struct {
+	ID             string    `bson:"_id" json:"id"`
+	State          string    `bson:"state" json:"state"`
+	RequestHash    string    `bson:"requestHash" json:"requestHash"`
+	ResponseStatus int       `bson:"responseStatus,omitempty" json:"responseStatus,omitempty"`
+	ResponseBody   []byte    `bson:"responseBody,omitempty" json:"-"`
+	CreatedAt      time.Time `bson:"createdAt" json:"createdAt"`
+	ExpiresAt      time.Time `bson:"expiresAt" json:"expiresAt"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types.
+const (
+	NotificationOrderPicked = "order_picked"
+)
+
+// Notification is an in-app message to a user.
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"userId" json:"-"`
+	OrderID   *primitive.ObjectID `bson:"orderId,omitempty" json:"orderId,omitempty"`
+	Type      string              `bson:"type" json:"type"`
+	Title     string              `bson:"title" json:"title"`
+	Message   string              `bson:"message" json:"message"`
+	ReadAt    *time.Time          `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
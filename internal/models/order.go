@@ -6,29 +6,91 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// OrderItem represents a single product entry within an order.
+// OrderItem represents a single product entry within an order. Price is the
+// charged unit price; OriginalPrice and CampaignID are set when a campaign
+// priced the line, and Discount is the promotion discount taken off it.
+// RefundedQuantity counts the units refunded so far; Picking is what staff
+// packed for the line. Unit is the product's unit at order time; quantities of
+// weighed lines may be decimal. ParentID and VariantName are set when the line
+// is a variant of a grouped product.
 type OrderItem struct {
-	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
-	Name      string             `bson:"name" json:"name"`
-	Price     float64            `bson:"price" json:"price"`
-	Quantity  int                `bson:"quantity" json:"quantity"`
+	ProductID        primitive.ObjectID  `bson:"productId" json:"productId"`
+	Name             string              `bson:"name" json:"name"`
+	Price            float64             `bson:"price" json:"price"`
+	Quantity         float64             `bson:"quantity" json:"quantity"`
+	Unit             string              `bson:"unit,omitempty" json:"unit,omitempty"`
+	ParentID         *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId,omitempty"`
+	VariantName      string              `bson:"variantName,omitempty" json:"variantName,omitempty"`
+	IsCampaign       bool                `bson:"isCampaign,omitempty" json:"isCampaign,omitempty"`
+	OriginalPrice    float64             `bson:"originalPrice,omitempty" json:"originalPrice,omitempty"`
+	CampaignID       *primitive.ObjectID `bson:"campaignId,omitempty" json:"campaignId,omitempty"`
+	Discount         float64             `bson:"discount,omitempty" json:"discount,omitempty"`
+	RefundedQuantity float64             `bson:"refundedQuantity,omitempty" json:"refundedQuantity,omitempty"`
+	Picking          *OrderItemPicking   `bson:"picking,omitempty" json:"picking,omitempty"`
+}
+
+// IsWeighed reports whether the line is sold by weight or volume.
+func (i OrderItem) IsWeighed() bool {
+	return i.Unit != "" && i.Unit != UnitPiece
 }
 
 // OrderCustomer captures lightweight customer contact details for an order.
+// AddressID is set when the address was copied from the user's saved
+// addresses.
 type OrderCustomer struct {
-	Title  string `bson:"title" json:"title"`
-	Detail string `bson:"detail" json:"detail"`
-	Note   string `bson:"note,omitempty" json:"note,omitempty"`
+	AddressID     string   `bson:"addressId,omitempty" json:"addressId,omitempty"`
+	Title         string   `bson:"title" json:"title"`
+	Detail        string   `bson:"detail" json:"detail"`
+	Note          string   `bson:"note,omitempty" json:"note,omitempty"`
+	City          string   `bson:"city,omitempty" json:"city,omitempty"`
+	District      string   `bson:"district,omitempty" json:"district,omitempty"`
+	Neighbourhood string   `bson:"neighbourhood,omitempty" json:"neighbourhood,omitempty"`
+	Latitude      *float64 `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude     *float64 `bson:"longitude,omitempty" json:"longitude,omitempty"`
+}
+
+// OrderCoupon snapshots the coupon applied to an order.
+type OrderCoupon struct {
+	CouponID primitive.ObjectID `bson:"couponId" json:"couponId"`
+	Code     string             `bson:"code" json:"code"`
+	Type     string             `bson:"type" json:"type"`
+	Value    float64            `bson:"value" json:"value"`
+	Discount float64            `bson:"discount" json:"discount"`
 }
 
-// Order defines the persisted order document.
+// Order defines the persisted order document. Subtotal is the items at their
+// charged prices, Discount the sum of Promotions and Coupon discounts, and
+// TotalPrice is Subtotal - Discount + DeliveryFee. Refunds never change these
+// totals; RefundedAmount sums them instead, including the differences found
+// when picking is completed at PickedAt. AmountDue is set once a refund is
+// deducted from an order not paid yet: it is what is left to collect.
 type Order struct {
-	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
-	UserID        *primitive.ObjectID `bson:"userId" json:"userId"`
-	Items         []OrderItem         `bson:"items" json:"items"`
-	TotalPrice    float64             `bson:"totalPrice" json:"totalPrice"`
-	Customer      OrderCustomer       `bson:"customer" json:"customer"`
-	PaymentMethod string              `bson:"paymentMethod" json:"paymentMethod"`
-	Status        string              `bson:"status" json:"status"`
-	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Number         string              `bson:"number,omitempty" json:"number,omitempty"`
+	UserID         *primitive.ObjectID `bson:"userId" json:"userId"`
+	Items          []OrderItem         `bson:"items" json:"items"`
+	Subtotal       float64             `bson:"subtotal,omitempty" json:"subtotal,omitempty"`
+	Discount       float64             `bson:"discount,omitempty" json:"discount,omitempty"`
+	DeliveryFee    float64             `bson:"deliveryFee" json:"deliveryFee"`
+	TotalPrice     float64             `bson:"totalPrice" json:"totalPrice"`
+	Promotions     []AppliedPromotion  `bson:"promotions,omitempty" json:"promotions,omitempty"`
+	Coupon         *OrderCoupon        `bson:"coupon,omitempty" json:"coupon,omitempty"`
+	Customer       OrderCustomer       `bson:"customer" json:"customer"`
+	DeliveryZone   *OrderDeliveryZone  `bson:"deliveryZone,omitempty" json:"deliveryZone,omitempty"`
+	DeliverySlot   *OrderDeliverySlot  `bson:"deliverySlot,omitempty" json:"deliverySlot,omitempty"`
+	PaymentMethod  string              `bson:"paymentMethod" json:"paymentMethod"`
+	PaymentStatus  string              `bson:"paymentStatus,omitempty" json:"paymentStatus,omitempty"`
+	Refunds        []OrderRefund       `bson:"refunds,omitempty" json:"refunds,omitempty"`
+	RefundedAmount float64             `bson:"refundedAmount,omitempty" json:"refundedAmount,omitempty"`
+	AmountDue      *float64            `bson:"amountDue,omitempty" json:"amountDue,omitempty"`
+	PickedAt       *time.Time          `bson:"pickedAt,omitempty" json:"pickedAt,omitempty"`
+	Status         string              `bson:"status" json:"status"`
+	StatusHistory  []OrderStatusChange `bson:"statusHistory" json:"statusHistory"`
+	CancelReason   string              `bson:"cancelReason,omitempty" json:"cancelReason,omitempty"`
+	CancelledAt    *time.Time          `bson:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
+	IsDeleted      bool                `bson:"isDeleted" json:"isDeleted,omitempty"`
+	DeletedAt      *time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	ArchivedAt     *time.Time          `bson:"archivedAt,omitempty" json:"archivedAt,omitempty"`
+	CreatedAt      time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time           `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
 }
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Order lifecycle statuses.
+const (
+	OrderStatusPending        = "pending"
+	OrderStatusConfirmed      = "confirmed"
+	OrderStatusPreparing      = "preparing"
+	OrderStatusOutForDelivery = "out_for_delivery"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCancelled      = "cancelled"
+	OrderStatusRejected       = "rejected"
+)
+
+// Actor types recorded on status history entries.
+const (
+	OrderActorAdmin  = "admin"
+	OrderActorUser   = "user"
+	OrderActorGuest  = "guest"
+	OrderActorSystem = "system"
+)
+
+// orderStatusTransitions lists the statuses reachable from each status.
+// Delivered, cancelled and rejected are terminal.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPending:        {OrderStatusConfirmed, OrderStatusCancelled, OrderStatusRejected},
+	OrderStatusConfirmed:      {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing:      {OrderStatusOutForDelivery, OrderStatusCancelled},
+	OrderStatusOutForDelivery: {OrderStatusDelivered},
+}
+
+// OrderStatusChange is a single entry of an order's status history.
+type OrderStatusChange struct {
+	From      string    `bson:"from,omitempty" json:"from,omitempty"`
+	To        string    `bson:"to" json:"to"`
+	ActorType string    `bson:"actorType" json:"actorType"`
+	ActorID   string    `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Note      string    `bson:"note,omitempty" json:"note,omitempty"`
+	ChangedAt time.Time `bson:"changedAt" json:"changedAt"`
+}
+
+// IsValidOrderStatus reports whether status is a known lifecycle status.
+func IsValidOrderStatus(status string) bool {
+	if _, ok := orderStatusTransitions[status]; ok {
+		return true
+	}
+	switch status {
+	case OrderStatusDelivered, OrderStatusCancelled, OrderStatusRejected:
+		return true
+	}
+	return false
+}
+
+// CanTransitionOrderStatus reports whether an order may move from one status
+// to another.
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOrderStatuses returns the statuses reachable from status.
+func NextOrderStatuses(status string) []string {
+	next := orderStatusTransitions[status]
+	out := make([]string, len(next))
+	copy(out, next)
+	return out
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Order payment statuses. Card orders move from unpaid through authorized to
+// paid on provider webhooks; cash orders are paid on delivery.
+const (
+	PaymentStatusUnpaid     = "unpaid"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusPaid       = "paid"
+	PaymentStatusRefunded   = "refunded"
+	PaymentStatusFailed     = "failed"
+)
+
+// Payment is a card payment attempt for an order at a payment provider.
+// Status mirrors the provider status (see the payments package). A failed
+// capture of an authorised payment is recorded in CaptureError and retried
+// at NextCaptureAt.
+type Payment struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID           primitive.ObjectID `bson:"orderId" json:"orderId"`
+	Provider          string             `bson:"provider" json:"provider"`
+	ProviderPaymentID string             `bson:"providerPaymentId,omitempty" json:"providerPaymentId,omitempty"`
+	Amount            float64            `bson:"amount" json:"amount"`
+	Currency          string             `bson:"currency" json:"currency"`
+	Status            string             `bson:"status" json:"status"`
+	RefundedAmount    float64            `bson:"refundedAmount,omitempty" json:"refundedAmount,omitempty"`
+	RedirectURL       string             `bson:"redirectUrl,omitempty" json:"redirectUrl,omitempty"`
+	FailureReason     string             `bson:"failureReason,omitempty" json:"failureReason,omitempty"`
+	CaptureAttempts   int                `bson:"captureAttempts,omitempty" json:"captureAttempts,omitempty"`
+	CaptureError      string             `bson:"captureError,omitempty" json:"captureError,omitempty"`
+	NextCaptureAt     *time.Time         `bson:"nextCaptureAt,omitempty" json:"nextCaptureAt,omitempty"`
+	CreatedAt         time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
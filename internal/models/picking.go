@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Picking statuses of an order line.
+const (
+	PickStatusPicked      = "picked"
+	PickStatusShortPicked = "short_picked"
+	PickStatusSubstituted = "substituted"
+)
+
+// OrderItemSubstitute is the product packed in place of missing units.
+type OrderItemSubstitute struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Name      string             `bson:"name" json:"name"`
+	Price     float64            `bson:"price" json:"price"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+}
+
+// OrderItemPicking is what staff packed for an order line. The line itself
+// keeps what the customer ordered. PickedQuantity is the amount of the ordered
+// product packed, the weighed amount for weighed lines; missing units may be
+// replaced by Substitute. OverQuantity is weight packed above the ordered
+// amount of a weighed line; the store absorbs it and it is not charged.
+// PriceDifference is the charge change for the line, set when picking is
+// completed; it is zero or negative because substitutes and overage never
+// cost the customer more than what they ordered.
+type OrderItemPicking struct {
+	Status          string               `bson:"status" json:"status"`
+	PickedQuantity  float64              `bson:"pickedQuantity" json:"pickedQuantity"`
+	OverQuantity    float64              `bson:"overQuantity,omitempty" json:"overQuantity,omitempty"`
+	Substitute      *OrderItemSubstitute `bson:"substitute,omitempty" json:"substitute,omitempty"`
+	PriceDifference float64              `bson:"priceDifference,omitempty" json:"priceDifference,omitempty"`
+	Note            string               `bson:"note,omitempty" json:"note,omitempty"`
+	PickedBy        string               `bson:"pickedBy,omitempty" json:"pickedBy,omitempty"`
+	PickedAt        time.Time            `bson:"pickedAt" json:"pickedAt"`
+}
@@ -15,11 +15,45 @@ type Product struct {
 	Description string             `bson:"description,omitempty" json:"description,omitempty"`
 	Barcode     string             `bson:"barcode,omitempty" json:"barcode,omitempty"`
 	Brand       string             `bson:"brand,omitempty" json:"brand,omitempty"`
-	Stock       int                `bson:"stock" json:"stock"`
+	Stock       float64            `bson:"stock" json:"stock"`
+	Reserved    float64            `bson:"reserved,omitempty" json:"reserved,omitempty"`
 	InStock     bool               `bson:"-" json:"inStock"`
 	IsActive    bool               `bson:"isActive" json:"isActive"`
 	IsCampaign  bool               `bson:"isCampaign" json:"isCampaign"`
 	IsDeleted   bool               `bson:"isDeleted" json:"isDeleted,omitempty"`
 	DeletedAt   *time.Time         `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+
+	// Unit is what Price, Stock and order quantities count; empty means
+	// UnitPiece. QuantityStep, MinQuantity and MaxQuantity limit order
+	// quantities, zero meaning the unit's default step, one step and no
+	// maximum.
+	Unit         string  `bson:"unit,omitempty" json:"unit"`
+	QuantityStep float64 `bson:"quantityStep,omitempty" json:"quantityStep,omitempty"`
+	MinQuantity  float64 `bson:"minQuantity,omitempty" json:"minQuantity,omitempty"`
+	MaxQuantity  float64 `bson:"maxQuantity,omitempty" json:"maxQuantity,omitempty"`
+
+	// Variants (sizes, flavours) are product documents of their own with
+	// ParentID set, so price, stock and barcode work as for any product. A
+	// parent with HasVariants only groups them and is never sold itself.
+	// Variants is filled on grouped reads.
+	ParentID    *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId,omitempty"`
+	VariantName string              `bson:"variantName,omitempty" json:"variantName,omitempty"`
+	HasVariants bool                `bson:"hasVariants,omitempty" json:"hasVariants,omitempty"`
+	Variants    []Product           `bson:"-" json:"variants,omitempty"`
+
+	// Campaign pricing is computed on read from active campaigns and never
+	// stored on the product.
+	OriginalPrice  float64             `bson:"-" json:"originalPrice,omitempty"`
+	CampaignPrice  *float64            `bson:"-" json:"campaignPrice,omitempty"`
+	CampaignEndsAt *time.Time          `bson:"-" json:"campaignEndsAt,omitempty"`
+	CampaignID     *primitive.ObjectID `bson:"-" json:"campaignId,omitempty"`
+}
+
+// AvailableStock returns the stock not held by active reservations.
+func (p Product) AvailableStock() float64 {
+	if available := RoundQuantity(p.Stock - p.Reserved); available > 0 {
+		return available
+	}
+	return 0
 }
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Product units. Price and stock are per unit; order quantities are counted
+// in the same unit.
+const (
+	UnitPiece = "piece"
+	UnitKg    = "kg"
+	UnitGram  = "g"
+	UnitLitre = "litre"
+)
+
+// defaultQuantitySteps is the quantity step of each unit when the product
+// does not set one.
+var defaultQuantitySteps = map[string]float64{
+	UnitPiece: 1,
+	UnitKg:    0.1,
+	UnitGram:  100,
+	UnitLitre: 0.5,
+}
+
+// quantityEpsilon absorbs float error when checking quantities against the
+// step.
+const quantityEpsilon = 1e-6
+
+// ValidUnit reports whether unit is a known product unit.
+func ValidUnit(unit string) bool {
+	_, ok := defaultQuantitySteps[unit]
+	return ok
+}
+
+// UnitOrDefault returns the product's unit; products without one are sold by
+// the piece.
+func (p Product) UnitOrDefault() string {
+	if p.Unit == "" {
+		return UnitPiece
+	}
+	return p.Unit
+}
+
+// IsWeighed reports whether the product is sold by weight or volume, so its
+// final quantity is known only after picking.
+func (p Product) IsWeighed() bool {
+	return p.UnitOrDefault() != UnitPiece
+}
+
+// Step returns the quantity step, falling back to the unit's default.
+func (p Product) Step() float64 {
+	if p.QuantityStep > 0 {
+		return p.QuantityStep
+	}
+	return defaultQuantitySteps[p.UnitOrDefault()]
+}
+
+// CheckQuantity reports why quantity cannot be ordered, or nil. Quantities
+// must be a multiple of the step and within the min/max limits; the minimum
+// defaults to one step.
+func (p Product) CheckQuantity(quantity float64) error {
+	step := p.Step()
+	minimum := p.MinQuantity
+	if minimum <= 0 {
+		minimum = step
+	}
+
+	if quantity < minimum-quantityEpsilon {
+		return fmt.Errorf("quantity must be at least %s %s", FormatQuantity(minimum), p.UnitOrDefault())
+	}
+	if p.MaxQuantity > 0 && quantity > p.MaxQuantity+quantityEpsilon {
+		return fmt.Errorf("quantity must be at most %s %s", FormatQuantity(p.MaxQuantity), p.UnitOrDefault())
+	}
+	if !IsMultipleOf(quantity, step) {
+		return fmt.Errorf("quantity must be a multiple of %s %s", FormatQuantity(step), p.UnitOrDefault())
+	}
+	return nil
+}
+
+// IsMultipleOf reports whether quantity is a whole number of steps.
+func IsMultipleOf(quantity, step float64) bool {
+	steps := quantity / step
+	return math.Abs(steps-math.Round(steps)) < quantityEpsilon
+}
+
+// RoundQuantity drops float noise from quantity arithmetic. Quantities keep
+// at most three decimals (grams of a kilogram).
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// FormatQuantity prints quantity without trailing zeros.
+func FormatQuantity(quantity float64) string {
+	return fmt.Sprintf("%g", RoundQuantity(quantity))
+}
@@ -0,0 +1,71 @@
+package models
+
+import "testing"
+
+// Quantities built from float arithmetic, such as 0.1 + 0.2 kg, must still
+// count as whole steps.
+func TestCheckQuantityToleratesFloatNoise(t *testing.T) {
+	cheese := Product{Unit: UnitKg}
+	for _, quantity := range []float64{0.1 + 0.2, 0.7, 3 * 0.1, 1.1 - 0.2} {
+		if err := cheese.CheckQuantity(quantity); err != nil {
+			t.Errorf("CheckQuantity(%v): %v", quantity, err)
+		}
+	}
+	if err := cheese.CheckQuantity(0.75); err == nil {
+		t.Error("0.75 kg is off the 0.1 kg step but was accepted")
+	}
+}
+
+func TestCheckQuantityDefaults(t *testing.T) {
+	// Products saved before units existed are sold by the piece.
+	legacy := Product{}
+	if legacy.IsWeighed() {
+		t.Error("a product without a unit is weighed")
+	}
+	if err := legacy.CheckQuantity(1.5); err == nil {
+		t.Error("half a piece was accepted")
+	}
+	if err := legacy.CheckQuantity(0); err == nil {
+		t.Error("zero pieces were accepted")
+	}
+
+	// Without a minimum one step is the least that can be ordered.
+	flour := Product{Unit: UnitGram}
+	if err := flour.CheckQuantity(50); err == nil {
+		t.Error("50 g was accepted below the 100 g step")
+	}
+	if err := flour.CheckQuantity(300); err != nil {
+		t.Errorf("300 g: %v", err)
+	}
+}
+
+func TestCheckQuantityLimits(t *testing.T) {
+	olives := Product{Unit: UnitKg, QuantityStep: 0.25, MinQuantity: 0.5, MaxQuantity: 2}
+
+	tests := map[float64]bool{
+		0.25: false, // one step, but below the minimum
+		0.5:  true,
+		1.75: true,
+		2:    true,
+		2.25: false,
+		0.6:  false, // within limits, off the custom step
+	}
+	for quantity, ok := range tests {
+		if err := olives.CheckQuantity(quantity); (err == nil) != ok {
+			t.Errorf("CheckQuantity(%v) = %v, want ok=%v", quantity, err, ok)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	for quantity, want := range map[float64]string{
+		1:         "1",
+		0.1 + 0.2: "0.3",
+		1.25:      "1.25",
+		0.0005:    "0.001",
+	} {
+		if got := FormatQuantity(quantity); got != want {
+			t.Errorf("FormatQuantity(%v) = %q, want %q", quantity, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Promotion types.
+const (
+	// PromotionMultiBuy is "X al Y öde": every BuyQuantity units of one
+	// product cost PayQuantity units.
+	PromotionMultiBuy = "multi_buy"
+	// PromotionBundle sells one set of BundleItems for BundlePrice.
+	PromotionBundle = "bundle"
+)
+
+// PromotionBundleItem is one product and quantity in a bundle set.
+type PromotionBundleItem struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+}
+
+// Promotion is a quantity-based basket rule. Multi-buy rules are scoped by
+// ProductIDs/Categories; bundle rules by BundleItems.
+type Promotion struct {
+	ID          primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	Name        string                `bson:"name" json:"name"`
+	Description string                `bson:"description,omitempty" json:"description,omitempty"`
+	Type        string                `bson:"type" json:"type"`
+	BuyQuantity int                   `bson:"buyQuantity,omitempty" json:"buyQuantity,omitempty"`
+	PayQuantity int                   `bson:"payQuantity,omitempty" json:"payQuantity,omitempty"`
+	ProductIDs  []primitive.ObjectID  `bson:"productIds,omitempty" json:"productIds,omitempty"`
+	Categories  []string              `bson:"categories,omitempty" json:"categories,omitempty"`
+	BundleItems []PromotionBundleItem `bson:"bundleItems,omitempty" json:"bundleItems,omitempty"`
+	BundlePrice float64               `bson:"bundlePrice,omitempty" json:"bundlePrice,omitempty"`
+	StartsAt    time.Time             `bson:"startsAt" json:"startsAt"`
+	EndsAt      time.Time             `bson:"endsAt" json:"endsAt"`
+	IsActive    bool                  `bson:"isActive" json:"isActive"`
+	CreatedAt   time.Time             `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time             `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AppliedPromotionLine is the share of a promotion's discount on one line.
+type AppliedPromotionLine struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	Discount  float64            `bson:"discount" json:"discount"`
+}
+
+// AppliedPromotion snapshots a promotion applied to a basket, so order totals
+// can be reproduced after the rule changes.
+type AppliedPromotion struct {
+	PromotionID primitive.ObjectID     `bson:"promotionId" json:"promotionId"`
+	Name        string                 `bson:"name" json:"name"`
+	Type        string                 `bson:"type" json:"type"`
+	Times       int                    `bson:"times" json:"times"`
+	Discount    float64                `bson:"discount" json:"discount"`
+	Lines       []AppliedPromotionLine `bson:"lines" json:"lines"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Refund statuses. Card refunds stay pending until the payment provider
+// accepts them, and are processing while the provider is being asked; cash
+// refunds are completed when recorded. Refunds on orders
+// not paid yet are deducted: nothing is paid back, the order's AmountDue
+// shrinks instead.
+const (
+	RefundStatusPending    = "pending"
+	RefundStatusProcessing = "processing"
+	RefundStatusCompleted  = "completed"
+	RefundStatusFailed     = "failed"
+	RefundStatusDeducted   = "deducted"
+)
+
+// OrderRefundItem is a refunded quantity of one order line. Amount is what
+// the quantity was charged after promotion and coupon discounts.
+type OrderRefundItem struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Name      string             `bson:"name" json:"name"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	Amount    float64            `bson:"amount" json:"amount"`
+}
+
+// OrderRefund records money given back on an order. Amount is the item
+// amounts plus DeliveryFee, which is only refunded with a full refund.
+// Restocked tells whether the items' stock was put back.
+type OrderRefund struct {
+	ID            primitive.ObjectID `bson:"id" json:"id"`
+	Items         []OrderRefundItem  `bson:"items,omitempty" json:"items,omitempty"`
+	DeliveryFee   float64            `bson:"deliveryFee,omitempty" json:"deliveryFee,omitempty"`
+	Amount        float64            `bson:"amount" json:"amount"`
+	Reason        string             `bson:"reason" json:"reason"`
+	Restocked     bool               `bson:"restocked" json:"restocked"`
+	Status        string             `bson:"status" json:"status"`
+	FailureReason string             `bson:"failureReason,omitempty" json:"failureReason,omitempty"`
+	ActorID       string             `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+	CompletedAt   *time.Time         `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stock movement reasons.
+const (
+	StockReasonSale             = "sale"
+	StockReasonCancel           = "cancel"
+	StockReasonManualAdjustment = "manual_adjustment"
+	StockReasonImport           = "import"
+	StockReasonReturn           = "return"
+)
+
+// StockMovement is an append-only ledger entry for a single stock change.
+type StockMovement struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ProductID  primitive.ObjectID  `bson:"productId" json:"productId"`
+	Delta      float64             `bson:"delta" json:"delta"`
+	StockAfter float64             `bson:"stockAfter" json:"stockAfter"`
+	Reason     string              `bson:"reason" json:"reason"`
+	ActorType  string              `bson:"actorType" json:"actorType"`
+	ActorID    string              `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	OrderID    *primitive.ObjectID `bson:"orderId,omitempty" json:"orderId,omitempty"`
+	Note       string              `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stock reservation statuses.
+const (
+	ReservationActive   = "active"
+	ReservationConsumed = "consumed"
+	ReservationReleased = "released"
+)
+
+// StockReservationItem is a quantity of one product held by a reservation.
+type StockReservationItem struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+}
+
+// StockReservation holds product quantities for a checkout until it expires
+// or an order consumes it. While active its quantities are counted in the
+// product's reserved field.
+type StockReservation struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID    *primitive.ObjectID    `bson:"userId" json:"userId"`
+	GuestID   string                 `bson:"guestId,omitempty" json:"-"`
+	Items     []StockReservationItem `bson:"items" json:"items"`
+	Status    string                 `bson:"status" json:"status"`
+	ExpiresAt time.Time              `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
@@ -6,13 +6,20 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Address represents a single address entry for a user.
+// Address represents a single address entry for a user. Coordinates and the
+// structured fields are optional; coordinates are used to find the delivery
+// zone.
 type Address struct {
-	ID        string `bson:"id" json:"id"`
-	Title     string `bson:"title" json:"title"`
-	Detail    string `bson:"detail" json:"detail"`
-	Note      string `bson:"note,omitempty" json:"note,omitempty"`
-	IsDefault bool   `bson:"isDefault" json:"isDefault"`
+	ID            string   `bson:"id" json:"id"`
+	Title         string   `bson:"title" json:"title"`
+	Detail        string   `bson:"detail" json:"detail"`
+	Note          string   `bson:"note,omitempty" json:"note,omitempty"`
+	City          string   `bson:"city,omitempty" json:"city,omitempty"`
+	District      string   `bson:"district,omitempty" json:"district,omitempty"`
+	Neighbourhood string   `bson:"neighbourhood,omitempty" json:"neighbourhood,omitempty"`
+	Latitude      *float64 `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude     *float64 `bson:"longitude,omitempty" json:"longitude,omitempty"`
+	IsDefault     bool     `bson:"isDefault" json:"isDefault"`
 }
 
 // User represents the application user account.
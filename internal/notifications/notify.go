@@ -0,0 +1,23 @@
+// Package notifications delivers messages to customers. Messages are stored
+// in the notifications collection and shown in the app.
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// Notify stores n for its user.
+func Notify(ctx context.Context, db *mongo.Database, n models.Notification) (models.Notification, error) {
+	n.ID = primitive.NewObjectID()
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	_, err := db.Collection("notifications").InsertOne(ctx, n)
+	return n, err
+}
@@ -0,0 +1,200 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeSignatureHeader carries the hex HMAC-SHA256 of a fake webhook body.
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider is an in-process provider for development and tests. 3-D
+// Secure succeeds unless the callback carries outcome=fail, and every state
+// change is posted back as a signed webhook when a WebhookURL is configured.
+type FakeProvider struct {
+	cfg    Config
+	client *http.Client
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	amount   float64
+	status   string
+	captured float64
+	refunded float64
+}
+
+// NewFakeProvider returns a fake provider signing webhooks with
+// cfg.WebhookSecret.
+func NewFakeProvider(cfg Config) *FakeProvider {
+	return &FakeProvider{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		payments: map[string]*fakePayment{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Initiate(_ context.Context, req InitiateRequest) (Initiation, error) {
+	if req.Amount <= 0 {
+		return Initiation{}, ErrInvalidAmount
+	}
+
+	id := "fake_" + randomHex(12)
+	p.mu.Lock()
+	p.payments[id] = &fakePayment{amount: req.Amount, status: StatusPending}
+	p.mu.Unlock()
+
+	// A real provider would host a 3-D Secure page; the fake sends the
+	// customer straight back to the callback.
+	redirect := strings.ReplaceAll(p.cfg.CallbackURL, "{reference}", url.PathEscape(req.Reference)) +
+		"?" + url.Values{"paymentId": {id}, "outcome": {"success"}}.Encode()
+
+	return Initiation{ProviderPaymentID: id, Status: StatusPending, RedirectURL: redirect}, nil
+}
+
+func (p *FakeProvider) Complete3DS(_ context.Context, providerPaymentID string, params url.Values) (Result, error) {
+	p.mu.Lock()
+	payment, ok := p.payments[providerPaymentID]
+	if !ok {
+		p.mu.Unlock()
+		return Result{}, ErrPaymentNotFound
+	}
+	result := Result{ProviderPaymentID: providerPaymentID, Amount: payment.amount}
+	event := Event{ProviderPaymentID: providerPaymentID, Amount: payment.amount}
+	if payment.status == StatusPending {
+		if params.Get("outcome") == "fail" {
+			payment.status = StatusFailed
+			event.Type = EventFailed
+			event.FailureReason = "3ds_failed"
+		} else {
+			payment.status = StatusAuthorized
+			event.Type = EventAuthorized
+		}
+	}
+	result.Status = payment.status
+	if payment.status == StatusFailed {
+		result.FailureReason = "3ds_failed"
+	}
+	p.mu.Unlock()
+
+	if event.Type != "" {
+		p.notify(event)
+	}
+	return result, nil
+}
+
+func (p *FakeProvider) Capture(_ context.Context, providerPaymentID string, amount float64) (Result, error) {
+	p.mu.Lock()
+	payment, ok := p.payments[providerPaymentID]
+	if !ok {
+		p.mu.Unlock()
+		return Result{}, ErrPaymentNotFound
+	}
+	if payment.status != StatusAuthorized || amount <= 0 || amount > payment.amount+0.001 {
+		p.mu.Unlock()
+		return Result{}, ErrInvalidAmount
+	}
+	payment.status = StatusCaptured
+	payment.captured = amount
+	p.mu.Unlock()
+
+	p.notify(Event{Type: EventCaptured, ProviderPaymentID: providerPaymentID, Amount: amount})
+	return Result{ProviderPaymentID: providerPaymentID, Status: StatusCaptured, Amount: amount}, nil
+}
+
+func (p *FakeProvider) Refund(_ context.Context, providerPaymentID string, amount float64) (Result, error) {
+	p.mu.Lock()
+	payment, ok := p.payments[providerPaymentID]
+	if !ok {
+		p.mu.Unlock()
+		return Result{}, ErrPaymentNotFound
+	}
+	if amount <= 0 || amount > payment.captured-payment.refunded+0.001 {
+		p.mu.Unlock()
+		return Result{}, ErrInvalidAmount
+	}
+	payment.refunded = math.Round((payment.refunded+amount)*100) / 100
+	if payment.refunded >= payment.captured {
+		payment.status = StatusRefunded
+	}
+	status := payment.status
+	p.mu.Unlock()
+
+	p.notify(Event{Type: EventRefunded, ProviderPaymentID: providerPaymentID, Amount: amount})
+	return Result{ProviderPaymentID: providerPaymentID, Status: status, Amount: amount}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(header http.Header, body []byte) (Event, error) {
+	expected := p.Sign(body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(FakeSignatureHeader))) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, err
+	}
+	return event, nil
+}
+
+// Sign returns the signature the fake provider sends with body.
+func (p *FakeProvider) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.cfg.WebhookSecret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// notify posts event to the webhook URL in the background, as a provider
+// would after the call returns.
+func (p *FakeProvider) notify(event Event) {
+	if p.cfg.WebhookURL == "" {
+		return
+	}
+	event.ID = "evt_" + randomHex(12)
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		req, err := http.NewRequest(http.MethodPost, p.cfg.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(FakeSignatureHeader, p.Sign(body))
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			log.Println("[PAYMENT] [ERROR] fake webhook delivery failed:", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(buf)
+}
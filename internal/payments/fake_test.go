@@ -0,0 +1,144 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func authorizedFakePayment(t *testing.T, p *FakeProvider, amount float64) string {
+	t.Helper()
+	ctx := context.Background()
+
+	initiation, err := p.Initiate(ctx, InitiateRequest{Reference: "pay_1", Amount: amount, Currency: "TRY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.Complete3DS(ctx, initiation.ProviderPaymentID, url.Values{"outcome": {"success"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusAuthorized {
+		t.Fatalf("3-D Secure status = %s, want %s", result.Status, StatusAuthorized)
+	}
+	return initiation.ProviderPaymentID
+}
+
+func TestFakeInitiateRedirectsToTheCallback(t *testing.T) {
+	p := NewFakeProvider(Config{CallbackURL: "https://shop.test/payments/{reference}/3ds"})
+
+	initiation, err := p.Initiate(context.Background(), InitiateRequest{Reference: "abc 1", Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(initiation.RedirectURL, "https://shop.test/payments/abc%201/3ds?") {
+		t.Errorf("redirect = %s, want the escaped reference in the callback", initiation.RedirectURL)
+	}
+	if _, err := p.Initiate(context.Background(), InitiateRequest{Reference: "x", Amount: 0}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("zero amount: err = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestFake3DSFailure(t *testing.T) {
+	p := NewFakeProvider(Config{})
+	ctx := context.Background()
+	initiation, _ := p.Initiate(ctx, InitiateRequest{Reference: "r", Amount: 50})
+
+	result, err := p.Complete3DS(ctx, initiation.ProviderPaymentID, url.Values{"outcome": {"fail"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusFailed || result.FailureReason == "" {
+		t.Errorf("result = %+v, want failed with a reason", result)
+	}
+	// A failed payment cannot be captured, and a second callback does not
+	// revive it.
+	again, _ := p.Complete3DS(ctx, initiation.ProviderPaymentID, url.Values{})
+	if again.Status != StatusFailed {
+		t.Errorf("second callback status = %s, want failed", again.Status)
+	}
+	if _, err := p.Capture(ctx, initiation.ProviderPaymentID, 50); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("capture of a failed payment: err = %v", err)
+	}
+}
+
+func TestFakeCaptureOnlyOnceAndWithinTheAuthorisation(t *testing.T) {
+	p := NewFakeProvider(Config{})
+	ctx := context.Background()
+	id := authorizedFakePayment(t, p, 100)
+
+	if _, err := p.Capture(ctx, id, 100.5); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("capture above the authorised amount: err = %v", err)
+	}
+	if _, err := p.Capture(ctx, "fake_unknown", 10); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("unknown payment: err = %v", err)
+	}
+	result, err := p.Capture(ctx, id, 100)
+	if err != nil || result.Status != StatusCaptured {
+		t.Fatalf("capture: %+v, %v", result, err)
+	}
+	if _, err := p.Capture(ctx, id, 100); err == nil {
+		t.Error("second capture succeeded")
+	}
+}
+
+func TestFakeRefundsUpToTheCapturedAmount(t *testing.T) {
+	p := NewFakeProvider(Config{})
+	ctx := context.Background()
+	id := authorizedFakePayment(t, p, 30)
+
+	if _, err := p.Refund(ctx, id, 10); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("refund before capture: err = %v", err)
+	}
+	if _, err := p.Capture(ctx, id, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := p.Refund(ctx, id, 10.1)
+	if err != nil || first.Status != StatusCaptured {
+		t.Fatalf("partial refund: %+v, %v", first, err)
+	}
+	if _, err := p.Refund(ctx, id, 20); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("refund above what is left: err = %v", err)
+	}
+	rest, err := p.Refund(ctx, id, 19.9)
+	if err != nil || rest.Status != StatusRefunded {
+		t.Errorf("refund of the rest: %+v, %v", rest, err)
+	}
+}
+
+func TestFakeWebhookSignature(t *testing.T) {
+	p := NewFakeProvider(Config{WebhookSecret: "s3cret"})
+	body := []byte(`{"id":"evt_1","type":"payment.captured","paymentId":"fake_1","amount":12.5}`)
+
+	signed := http.Header{}
+	signed.Set(FakeSignatureHeader, p.Sign(body))
+	event, err := p.ParseWebhook(signed, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventCaptured || event.ProviderPaymentID != "fake_1" || event.Amount != 12.5 {
+		t.Errorf("event = %+v", event)
+	}
+
+	otherSecret := http.Header{}
+	otherSecret.Set(FakeSignatureHeader, NewFakeProvider(Config{WebhookSecret: "other"}).Sign(body))
+	tampered := []byte(strings.Replace(string(body), "12.5", "1250", 1))
+
+	for name, tt := range map[string]struct {
+		header http.Header
+		body   []byte
+	}{
+		"missing signature":   {http.Header{}, body},
+		"other secret":        {otherSecret, body},
+		"tampered body":       {signed, tampered},
+		"signature uppercase": {http.Header{FakeSignatureHeader: {strings.ToUpper(p.Sign(body))}}, body},
+	} {
+		if _, err := p.ParseWebhook(tt.header, tt.body); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package payments defines the card payment provider interface used by
+// checkout, and the providers the backend can be configured with.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Provider-side payment statuses, as reported by results and webhook events.
+const (
+	StatusPending    = "pending"
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusFailed     = "failed"
+)
+
+// Webhook event types.
+const (
+	EventAuthorized = "payment.authorized"
+	EventCaptured   = "payment.captured"
+	EventFailed     = "payment.failed"
+	EventRefunded   = "payment.refunded"
+)
+
+var (
+	// ErrInvalidSignature means a webhook could not be verified.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrPaymentNotFound means the provider does not know the payment.
+	ErrPaymentNotFound = errors.New("payment not found at provider")
+	// ErrInvalidAmount means a capture or refund amount is not allowed.
+	ErrInvalidAmount = errors.New("invalid payment amount")
+)
+
+// InitiateRequest starts a card payment. Reference is our payment id; the
+// provider sends the customer back to its 3-D Secure callback with it.
+type InitiateRequest struct {
+	Reference string
+	Amount    float64
+	Currency  string
+}
+
+// Initiation is a started payment. The customer completes 3-D Secure at
+// RedirectURL.
+type Initiation struct {
+	ProviderPaymentID string
+	Status            string
+	RedirectURL       string
+}
+
+// Result is the state of a payment after a provider call.
+type Result struct {
+	ProviderPaymentID string
+	Status            string
+	Amount            float64
+	FailureReason     string
+}
+
+// Event is a verified webhook notification.
+type Event struct {
+	ID                string  `json:"id"`
+	Type              string  `json:"type"`
+	ProviderPaymentID string  `json:"paymentId"`
+	Amount            float64 `json:"amount"`
+	FailureReason     string  `json:"failureReason,omitempty"`
+}
+
+// Provider is a card payment provider. Payments are initiated, authorised by
+// the customer through 3-D Secure, captured and optionally refunded. Only
+// webhook events parsed by ParseWebhook are trusted to move money state.
+type Provider interface {
+	Name() string
+	Initiate(ctx context.Context, req InitiateRequest) (Initiation, error)
+	// Complete3DS finishes 3-D Secure with the parameters the provider sent
+	// the customer back with.
+	Complete3DS(ctx context.Context, providerPaymentID string, params url.Values) (Result, error)
+	Capture(ctx context.Context, providerPaymentID string, amount float64) (Result, error)
+	Refund(ctx context.Context, providerPaymentID string, amount float64) (Result, error)
+	// ParseWebhook verifies and decodes a webhook request body, returning
+	// ErrInvalidSignature when it was not sent by the provider.
+	ParseWebhook(header http.Header, body []byte) (Event, error)
+}
+
+// Config configures a provider. CallbackURL is where the customer returns
+// after 3-D Secure; its {reference} placeholder is replaced with the payment
+// reference.
+type Config struct {
+	CallbackURL   string
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// New returns the provider called name.
+func New(name string, cfg Config) (Provider, error) {
+	switch name {
+	case "fake":
+		return NewFakeProvider(cfg), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
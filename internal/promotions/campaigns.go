@@ -0,0 +1,127 @@
+package promotions
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// ActiveCampaigns returns the campaigns running at now.
+func ActiveCampaigns(ctx context.Context, db *mongo.Database, now time.Time) ([]models.Campaign, error) {
+	cursor, err := db.Collection("campaigns").Find(ctx, bson.M{
+		"isActive": true,
+		"startsAt": bson.M{"$lte": now},
+		"endsAt":   bson.M{"$gt": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	campaigns := []models.Campaign{}
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// CampaignScope returns a products filter matching everything covered by
+// campaigns, or nil when no campaign covers anything.
+func CampaignScope(campaigns []models.Campaign) bson.M {
+	var ids []interface{}
+	var categories []string
+	for _, campaign := range campaigns {
+		for _, id := range campaign.ProductIDs {
+			ids = append(ids, id)
+		}
+		categories = append(categories, campaign.Categories...)
+	}
+
+	var or []bson.M
+	if len(ids) > 0 {
+		or = append(or, bson.M{"_id": bson.M{"$in": ids}})
+	}
+	if len(categories) > 0 {
+		or = append(or, bson.M{"category": bson.M{"$in": categories}})
+	}
+	if len(or) == 0 {
+		return nil
+	}
+	return bson.M{"$or": or}
+}
+
+// ApplyCampaigns prices product with the cheapest campaign covering it.
+// Price becomes the price to charge and OriginalPrice keeps the list price;
+// IsCampaign reports whether a campaign applied.
+func ApplyCampaigns(product *models.Product, campaigns []models.Campaign) {
+	product.OriginalPrice = product.Price
+	product.IsCampaign = false
+	product.CampaignPrice = nil
+	product.CampaignEndsAt = nil
+	product.CampaignID = nil
+
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		if !covers(campaign.ProductIDs, campaign.Categories, *product) {
+			continue
+		}
+
+		price := discountedPrice(*campaign, product.OriginalPrice)
+		if price >= product.OriginalPrice {
+			continue
+		}
+		if product.CampaignPrice != nil && price >= *product.CampaignPrice {
+			continue
+		}
+
+		endsAt := campaign.EndsAt
+		id := campaign.ID
+		product.CampaignPrice = &price
+		product.CampaignEndsAt = &endsAt
+		product.CampaignID = &id
+	}
+
+	if product.CampaignPrice != nil {
+		product.IsCampaign = true
+		product.Price = *product.CampaignPrice
+	}
+}
+
+func discountedPrice(campaign models.Campaign, listPrice float64) float64 {
+	var price float64
+	switch campaign.DiscountType {
+	case models.CampaignDiscountPercent:
+		price = listPrice * (1 - campaign.Value/100)
+	case models.CampaignDiscountFixedPrice:
+		price = campaign.Value
+	default:
+		return listPrice
+	}
+	if price < 0 {
+		price = 0
+	}
+	return roundPrice(price)
+}
+
+// covers reports whether product is one of ids or in one of categories.
+func covers(ids []primitive.ObjectID, categories []string, product models.Product) bool {
+	for _, id := range ids {
+		if id == product.ID {
+			return true
+		}
+	}
+	for _, category := range categories {
+		for _, productCategory := range product.Category {
+			if strings.EqualFold(category, productCategory) {
+				return true
+			}
+		}
+	}
+	return false
+}
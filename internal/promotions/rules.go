@@ -0,0 +1,212 @@
+package promotions
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// ActivePromotions returns the multi-buy and bundle rules running at now.
+func ActivePromotions(ctx context.Context, db *mongo.Database, now time.Time) ([]models.Promotion, error) {
+	cursor, err := db.Collection("promotions").Find(ctx, bson.M{
+		"isActive": true,
+		"startsAt": bson.M{"$lte": now},
+		"endsAt":   bson.M{"$gt": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	promotions := []models.Promotion{}
+	if err := cursor.All(ctx, &promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// EvaluatePromotions applies rules to the priced items and returns what was
+// applied. Each unit counts towards at most one promotion: bundles are
+// matched first, best saving first, and multi-buy rules take the remaining
+// units, picking the best rule per product. products supplies categories.
+// Promotions count whole units, so weighed products never take part.
+func EvaluatePromotions(items []models.OrderItem, products map[primitive.ObjectID]models.Product, rules []models.Promotion) []models.AppliedPromotion {
+	prices := make(map[primitive.ObjectID]float64, len(items))
+	remaining := make(map[primitive.ObjectID]int, len(items))
+	for _, item := range items {
+		prices[item.ProductID] = item.Price
+		if products[item.ProductID].IsWeighed() {
+			continue
+		}
+		remaining[item.ProductID] += int(item.Quantity)
+	}
+
+	var bundles, multiBuys []models.Promotion
+	for _, rule := range rules {
+		switch rule.Type {
+		case models.PromotionBundle:
+			if bundleSaving(rule, prices) > 0 {
+				bundles = append(bundles, rule)
+			}
+		case models.PromotionMultiBuy:
+			if rule.BuyQuantity > rule.PayQuantity && rule.PayQuantity >= 0 {
+				multiBuys = append(multiBuys, rule)
+			}
+		}
+	}
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return bundleSaving(bundles[i], prices) > bundleSaving(bundles[j], prices)
+	})
+
+	applied := []models.AppliedPromotion{}
+	for _, rule := range bundles {
+		if result, ok := applyBundle(rule, prices, remaining); ok {
+			applied = append(applied, result)
+		}
+	}
+
+	// Multi-buy rules are per product; keep the best rule for each line.
+	byRule := map[primitive.ObjectID]*models.AppliedPromotion{}
+	var order []primitive.ObjectID
+	for _, item := range items {
+		qty := remaining[item.ProductID]
+		if qty == 0 {
+			continue
+		}
+
+		var best *models.Promotion
+		var bestFree int
+		for i := range multiBuys {
+			rule := &multiBuys[i]
+			if !covers(rule.ProductIDs, rule.Categories, products[item.ProductID]) {
+				continue
+			}
+			free := (qty / rule.BuyQuantity) * (rule.BuyQuantity - rule.PayQuantity)
+			if free > bestFree {
+				best, bestFree = rule, free
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		times := qty / best.BuyQuantity
+		used := times * best.BuyQuantity
+		remaining[item.ProductID] -= used
+		discount := roundPrice(float64(bestFree) * item.Price)
+
+		result, ok := byRule[best.ID]
+		if !ok {
+			result = &models.AppliedPromotion{
+				PromotionID: best.ID,
+				Name:        best.Name,
+				Type:        best.Type,
+			}
+			byRule[best.ID] = result
+			order = append(order, best.ID)
+		}
+		result.Times += times
+		result.Discount = roundPrice(result.Discount + discount)
+		result.Lines = append(result.Lines, models.AppliedPromotionLine{
+			ProductID: item.ProductID,
+			Quantity:  used,
+			Discount:  discount,
+		})
+	}
+	for _, id := range order {
+		applied = append(applied, *byRule[id])
+	}
+
+	return applied
+}
+
+// LineDiscounts sums the promotion discounts per product.
+func LineDiscounts(applied []models.AppliedPromotion) map[primitive.ObjectID]float64 {
+	out := map[primitive.ObjectID]float64{}
+	for _, promotion := range applied {
+		for _, line := range promotion.Lines {
+			out[line.ProductID] = roundPrice(out[line.ProductID] + line.Discount)
+		}
+	}
+	return out
+}
+
+func applyBundle(rule models.Promotion, prices map[primitive.ObjectID]float64, remaining map[primitive.ObjectID]int) (models.AppliedPromotion, bool) {
+	times := -1
+	for _, part := range rule.BundleItems {
+		if part.Quantity <= 0 {
+			return models.AppliedPromotion{}, false
+		}
+		sets := remaining[part.ProductID] / part.Quantity
+		if times < 0 || sets < times {
+			times = sets
+		}
+	}
+	if times <= 0 {
+		return models.AppliedPromotion{}, false
+	}
+
+	setValue := bundleValue(rule, prices)
+	total := roundPrice(bundleSaving(rule, prices) * float64(times))
+	result := models.AppliedPromotion{
+		PromotionID: rule.ID,
+		Name:        rule.Name,
+		Type:        rule.Type,
+		Times:       times,
+		Discount:    total,
+	}
+
+	// Spread the saving over the bundle lines by value; the last line takes
+	// the rounding remainder so the lines add up to the total.
+	left := total
+	for i, part := range rule.BundleItems {
+		quantity := part.Quantity * times
+		remaining[part.ProductID] -= quantity
+
+		share := left
+		if i < len(rule.BundleItems)-1 {
+			share = roundPrice(total * prices[part.ProductID] * float64(part.Quantity) / setValue)
+			left = roundPrice(left - share)
+		}
+		result.Lines = append(result.Lines, models.AppliedPromotionLine{
+			ProductID: part.ProductID,
+			Quantity:  quantity,
+			Discount:  share,
+		})
+	}
+	return result, true
+}
+
+func bundleValue(rule models.Promotion, prices map[primitive.ObjectID]float64) float64 {
+	var value float64
+	for _, part := range rule.BundleItems {
+		price, ok := prices[part.ProductID]
+		if !ok {
+			return 0
+		}
+		value += price * float64(part.Quantity)
+	}
+	return value
+}
+
+func bundleSaving(rule models.Promotion, prices map[primitive.ObjectID]float64) float64 {
+	if len(rule.BundleItems) == 0 {
+		return 0
+	}
+	value := bundleValue(rule, prices)
+	if value <= rule.BundlePrice {
+		return 0
+	}
+	return value - rule.BundlePrice
+}
+
+func roundPrice(value float64) float64 {
+	return math.Round(value*100) / 100
+}
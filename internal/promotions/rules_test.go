@@ -0,0 +1,149 @@
+package promotions
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func multiBuyRule(buy, pay int, categories ...string) models.Promotion {
+	return models.Promotion{
+		ID:          primitive.NewObjectID(),
+		Type:        models.PromotionMultiBuy,
+		BuyQuantity: buy,
+		PayQuantity: pay,
+		Categories:  categories,
+	}
+}
+
+func bundleRule(price float64, parts ...primitive.ObjectID) models.Promotion {
+	rule := models.Promotion{ID: primitive.NewObjectID(), Type: models.PromotionBundle, BundlePrice: price}
+	for _, id := range parts {
+		rule.BundleItems = append(rule.BundleItems, models.PromotionBundleItem{ProductID: id, Quantity: 1})
+	}
+	return rule
+}
+
+// The bundle saving is split over the lines by value; a three-way split of
+// 10.00 does not divide evenly and the last line must absorb the cent.
+func TestBundleLinesAddUpToTheDiscount(t *testing.T) {
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	items := []models.OrderItem{
+		{ProductID: a, Price: 10, Quantity: 2},
+		{ProductID: b, Price: 10, Quantity: 2},
+		{ProductID: c, Price: 10, Quantity: 2},
+	}
+
+	applied := EvaluatePromotions(items, nil, []models.Promotion{bundleRule(20, a, b, c)})
+	if len(applied) != 1 {
+		t.Fatalf("applied %d promotions, want 1", len(applied))
+	}
+	got := applied[0]
+	if got.Times != 2 || got.Discount != 20 {
+		t.Fatalf("times=%d discount=%v, want 2 and 20", got.Times, got.Discount)
+	}
+
+	var sum float64
+	for _, line := range got.Lines {
+		sum += line.Discount
+	}
+	if roundPrice(sum) != got.Discount {
+		t.Errorf("line discounts sum to %v, want %v", sum, got.Discount)
+	}
+	if last := got.Lines[2].Discount; last != 6.66 {
+		t.Errorf("last line discount = %v, want the 6.66 remainder", last)
+	}
+}
+
+// Overlapping multi-buy rules on one product: the rule giving more free
+// units wins and the other is not applied to the same units.
+func TestOverlappingMultiBuyRulesPickTheBest(t *testing.T) {
+	apple := primitive.NewObjectID()
+	products := map[primitive.ObjectID]models.Product{apple: {ID: apple, Category: []string{"meyve"}}}
+	threeForTwo := multiBuyRule(3, 2, "meyve")
+	twoForOne := multiBuyRule(2, 1, "meyve")
+
+	cases := map[float64]primitive.ObjectID{
+		3: threeForTwo.ID, // 2-for-1 also frees one unit; the first found stays
+		4: twoForOne.ID,   // two free units beat one
+	}
+	for quantity, want := range cases {
+		items := []models.OrderItem{{ProductID: apple, Price: 5, Quantity: quantity}}
+		applied := EvaluatePromotions(items, products, []models.Promotion{threeForTwo, twoForOne})
+		if len(applied) != 1 || applied[0].PromotionID != want {
+			t.Errorf("quantity %v: applied %+v, want only %s", quantity, applied, want.Hex())
+		}
+	}
+}
+
+// Units matched by a bundle are gone before multi-buy rules run, and of two
+// bundles competing for the same units the bigger saving goes first.
+func TestBundlesClaimUnitsFirst(t *testing.T) {
+	tea, cup, pot := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	products := map[primitive.ObjectID]models.Product{
+		tea: {ID: tea, Category: []string{"içecek"}},
+		cup: {ID: cup},
+		pot: {ID: pot},
+	}
+	items := []models.OrderItem{
+		{ProductID: tea, Price: 30, Quantity: 3},
+		{ProductID: cup, Price: 20, Quantity: 1},
+		{ProductID: pot, Price: 100, Quantity: 1},
+	}
+	small := bundleRule(45, tea, cup) // saves 5
+	big := bundleRule(100, tea, pot)  // saves 30
+
+	applied := EvaluatePromotions(items, products, []models.Promotion{small, big, multiBuyRule(3, 2, "içecek")})
+
+	var ids []primitive.ObjectID
+	for _, promotion := range applied {
+		ids = append(ids, promotion.PromotionID)
+	}
+	if len(ids) != 2 || ids[0] != big.ID || ids[1] != small.ID {
+		t.Fatalf("applied %v, want the big bundle, then the small one and no multi-buy", ids)
+	}
+	if discounts := LineDiscounts(applied); discounts[tea] != roundPrice(30*30.0/130+5*30.0/50) {
+		t.Errorf("tea discount = %v", discounts[tea])
+	}
+}
+
+func TestMultiBuyRespectsScope(t *testing.T) {
+	milk, soap := primitive.NewObjectID(), primitive.NewObjectID()
+	products := map[primitive.ObjectID]models.Product{
+		milk: {ID: milk, Category: []string{"Süt"}},
+		soap: {ID: soap, Category: []string{"temizlik"}},
+	}
+	items := []models.OrderItem{
+		{ProductID: milk, Price: 12, Quantity: 3},
+		{ProductID: soap, Price: 40, Quantity: 3},
+	}
+
+	applied := EvaluatePromotions(items, products, []models.Promotion{multiBuyRule(3, 2, "süt")})
+	if len(applied) != 1 || len(applied[0].Lines) != 1 || applied[0].Lines[0].ProductID != milk {
+		t.Fatalf("applied %+v, want the rule on milk only", applied)
+	}
+	if applied[0].Discount != 12 {
+		t.Errorf("discount = %v, want 12", applied[0].Discount)
+	}
+}
+
+// Promotions count whole units, so weighed products never take part even
+// when their quantity would fill a rule.
+func TestWeighedProductsAreLeftOut(t *testing.T) {
+	cheese, bread := primitive.NewObjectID(), primitive.NewObjectID()
+	products := map[primitive.ObjectID]models.Product{
+		cheese: {ID: cheese, Unit: models.UnitKg, Category: []string{"şarküteri"}},
+		bread:  {ID: bread, Category: []string{"şarküteri"}},
+	}
+	items := []models.OrderItem{
+		{ProductID: cheese, Price: 80, Quantity: 3, Unit: models.UnitKg},
+		{ProductID: bread, Price: 10, Quantity: 1},
+	}
+	rules := []models.Promotion{multiBuyRule(3, 2, "şarküteri"), bundleRule(50, cheese, bread)}
+
+	if applied := EvaluatePromotions(items, products, rules); len(applied) != 0 {
+		t.Errorf("applied %+v to a weighed product", applied)
+	}
+}
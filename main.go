@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/handlers"
+	"backend/internal/jobs"
 	"backend/internal/middleware"
+	"backend/internal/payments"
 )
 
 func main() {
@@ -25,15 +29,75 @@ func main() {
 	log.Println("MongoDB connected to:", db.Name())
 
 	if err := database.EnsureProductIndexes(db); err != nil {
-		log.Println("⚠️ product index warning: %v", err)
+		log.Printf("⚠️ product index warning: %v", err)
 	}
 	if err := database.EnsureUserIndexes(db); err != nil {
-		log.Println("⚠️ user index warning: %v", err)
+		log.Printf("⚠️ user index warning: %v", err)
 	}
 	if err := database.EnsureOrderIndexes(db); err != nil {
-		log.Println("⚠️ order index warning: %v", err)
+		log.Printf("⚠️ order index warning: %v", err)
+	}
+	if err := database.EnsureIdempotencyIndexes(db); err != nil {
+		log.Printf("⚠️ idempotency index warning: %v", err)
+	}
+	if err := database.EnsureReservationIndexes(db); err != nil {
+		log.Printf("⚠️ reservation index warning: %v", err)
+	}
+	if err := database.EnsureStockMovementIndexes(db); err != nil {
+		log.Printf("⚠️ stock movement index warning: %v", err)
+	}
+	if err := database.EnsureCartIndexes(db); err != nil {
+		log.Printf("⚠️ cart index warning: %v", err)
+	}
+	if err := database.EnsureCouponIndexes(db); err != nil {
+		log.Printf("⚠️ coupon index warning: %v", err)
+	}
+	if err := database.EnsureCampaignIndexes(db); err != nil {
+		log.Printf("⚠️ campaign index warning: %v", err)
+	}
+	if err := database.EnsurePromotionIndexes(db); err != nil {
+		log.Printf("⚠️ promotion index warning: %v", err)
+	}
+	if err := database.EnsurePaymentIndexes(db); err != nil {
+		log.Printf("⚠️ payment index warning: %v", err)
+	}
+	if err := database.EnsureDeliveryZoneIndexes(db); err != nil {
+		log.Printf("⚠️ delivery zone index warning: %v", err)
+	}
+	if err := database.EnsureNotificationIndexes(db); err != nil {
+		log.Printf("⚠️ notification index warning: %v", err)
 	}
 
+	// Card payments are opt-in: without a provider only cash orders are
+	// taken and the payment routes are not registered.
+	var paymentProvider payments.Provider
+	if config.AppEnv.PaymentProvider == "" {
+		log.Println("⚠️ PAYMENT_PROVIDER not set, card payments disabled")
+	} else {
+		if config.AppEnv.PaymentProvider == "fake" && !config.AppEnv.PaymentAllowFake {
+			log.Fatal("PAYMENT_PROVIDER=fake approves every payment; set PAYMENT_ALLOW_FAKE=true for development only")
+		}
+		if config.AppEnv.PaymentWebhookSecret == "" {
+			log.Fatal("PAYMENT_WEBHOOK_SECRET is required with PAYMENT_PROVIDER")
+		}
+
+		paymentProvider, err = payments.New(config.AppEnv.PaymentProvider, payments.Config{
+			CallbackURL:   config.AppEnv.PublicBaseURL + "/payments/{reference}/3ds",
+			WebhookURL:    config.AppEnv.PublicBaseURL + "/payments/webhook",
+			WebhookSecret: config.AppEnv.PaymentWebhookSecret,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		jobs.StartPaymentCaptureRetrier(time.Minute, func(ctx context.Context) (int, error) {
+			return handlers.RetryPaymentCaptures(ctx, db, paymentProvider)
+		})
+	}
+
+	jobs.StartOrderArchiver(db, config.AppEnv.OrderArchiveAfter, config.AppEnv.OrderArchiveInterval)
+	jobs.StartReservationSweeper(db, time.Minute)
+
 	r := gin.Default()
 	r.LoadHTMLGlob("templates/**/*")
 	r.Static("/public", "./public")
@@ -65,8 +129,25 @@ func main() {
 	r.GET("/products", handlers.GetProducts(db))
 	r.GET("/categories", handlers.GetCategories(db))
 	r.GET("/products/campaign", handlers.GetCampaignProducts(db))
-	r.POST("/orders", handlers.CreateOrder(db, config.AppEnv.JWTSecret))
-	r.GET("/orders", handlers.GetOrders(db))
+	r.GET("/cart", handlers.GetGuestCart(db, config.AppEnv.JWTSecret, config.AppEnv.GuestCartTTL))
+	r.POST("/cart/items", handlers.AddGuestCartItem(db, config.AppEnv.JWTSecret, config.AppEnv.GuestCartTTL))
+	r.PUT("/cart/items/:productId", handlers.SetGuestCartItemQuantity(db, config.AppEnv.JWTSecret, config.AppEnv.GuestCartTTL))
+	r.DELETE("/cart/items/:productId", handlers.RemoveGuestCartItem(db, config.AppEnv.JWTSecret, config.AppEnv.GuestCartTTL))
+	r.DELETE("/cart", handlers.ClearGuestCart(db, config.AppEnv.JWTSecret, config.AppEnv.GuestCartTTL))
+	r.POST("/cart/reserve", handlers.ReserveStock(db, config.AppEnv.JWTSecret, config.AppEnv.StockReservationTTL))
+	r.DELETE("/cart/reserve/:id", handlers.ReleaseReservation(db, config.AppEnv.JWTSecret))
+	r.GET("/delivery/check", handlers.CheckDeliveryLocation(db))
+	r.GET("/delivery/slots", handlers.GetDeliverySlots(db, config.AppEnv.DeliveryLocation))
+	if paymentProvider != nil {
+		r.GET("/payments/:id/3ds", handlers.PaymentCallback(db, paymentProvider))
+		r.POST("/payments/:id/3ds", handlers.PaymentCallback(db, paymentProvider))
+		r.POST("/payments/webhook", handlers.PaymentWebhook(db, paymentProvider))
+	}
+	r.POST("/checkout/quote", handlers.CheckoutQuote(db, config.AppEnv.JWTSecret))
+	r.POST("/orders",
+		handlers.Idempotency(db, config.AppEnv.JWTSecret, config.AppEnv.IdempotencyTTL),
+		handlers.CreateOrder(db, config.AppEnv.JWTSecret, config.AppEnv.DailyOrderNumbers, config.AppEnv.DeliveryLocation, paymentProvider),
+	)
 
 	user := r.Group("/user")
 	user.Use(middleware.UserAuth(config.AppEnv.JWTSecret))
@@ -75,6 +156,19 @@ func main() {
 		user.POST("/addresses", handlers.CreateUserAddress(db))
 		user.PUT("/addresses/:id", handlers.UpdateUserAddress(db))
 		user.DELETE("/addresses/:id", handlers.DeleteUserAddress(db))
+
+		user.GET("/orders", handlers.GetUserOrders(db))
+		user.POST("/orders/:id/cancel", handlers.CancelUserOrder(db, paymentProvider))
+
+		user.GET("/notifications", handlers.GetUserNotifications(db))
+		user.POST("/notifications/:id/read", handlers.MarkUserNotificationRead(db))
+
+		user.GET("/cart", handlers.GetUserCart(db))
+		user.POST("/cart/items", handlers.AddUserCartItem(db))
+		user.PUT("/cart/items/:productId", handlers.SetUserCartItemQuantity(db))
+		user.DELETE("/cart/items/:productId", handlers.RemoveUserCartItem(db))
+		user.DELETE("/cart", handlers.ClearUserCart(db))
+		user.POST("/cart/checkout", handlers.CheckoutUserCart(db, config.AppEnv.DailyOrderNumbers, config.AppEnv.DeliveryLocation, paymentProvider))
 	}
 
 	admin := r.Group("/admin/api")
@@ -88,12 +182,49 @@ func main() {
 		admin.POST("/products", handlers.CreateProduct(db))
 		admin.PUT("/products/:id", handlers.UpdateProduct(db))
 		admin.DELETE("/products/:id", handlers.DeleteProduct(db))
+		admin.GET("/products/:id/stock-history", handlers.GetProductStockHistory(db))
+		admin.GET("/products/:id/variants", handlers.GetProductVariants(db))
+		admin.POST("/products/:id/variants", handlers.CreateProductVariant(db))
 
 		admin.GET("/categories", handlers.GetAllCategories(db))
 		admin.POST("/categories", handlers.CreateCategory(db))
 		admin.PUT("/categories/:id", handlers.UpdateCategory(db))
 		admin.DELETE("/categories/:id", handlers.DeleteCategory(db))
 
+		admin.GET("/settings/delivery", handlers.GetDeliverySettings(db))
+		admin.PUT("/settings/delivery", handlers.UpdateDeliverySettings(db))
+		admin.GET("/settings/delivery-slots", handlers.GetDeliverySlotSchedule(db))
+		admin.PUT("/settings/delivery-slots", handlers.UpdateDeliverySlotSchedule(db))
+
+		admin.GET("/delivery-zones", handlers.GetAllDeliveryZones(db))
+		admin.POST("/delivery-zones", handlers.CreateDeliveryZone(db))
+		admin.PUT("/delivery-zones/:id", handlers.UpdateDeliveryZone(db))
+		admin.DELETE("/delivery-zones/:id", handlers.DeleteDeliveryZone(db))
+
+		admin.GET("/campaigns", handlers.GetAllCampaigns(db))
+		admin.POST("/campaigns", handlers.CreateCampaign(db))
+		admin.PUT("/campaigns/:id", handlers.UpdateCampaign(db))
+		admin.DELETE("/campaigns/:id", handlers.DeleteCampaign(db))
+
+		admin.GET("/promotions", handlers.GetAllPromotions(db))
+		admin.POST("/promotions", handlers.CreatePromotion(db))
+		admin.PUT("/promotions/:id", handlers.UpdatePromotion(db))
+		admin.DELETE("/promotions/:id", handlers.DeletePromotion(db))
+
+		admin.GET("/coupons", handlers.GetAllCoupons(db))
+		admin.POST("/coupons", handlers.CreateCoupon(db))
+		admin.PUT("/coupons/:id", handlers.UpdateCoupon(db))
+		admin.DELETE("/coupons/:id", handlers.DeleteCoupon(db))
+
+		admin.GET("/orders", handlers.GetAllOrders(db))
+		admin.GET("/orders/archived", handlers.GetArchivedOrders(db))
+		admin.POST("/orders/:id/restore", handlers.RestoreOrder(db))
+		admin.PATCH("/orders/:id/status", handlers.UpdateOrderStatus(db, paymentProvider))
+		admin.POST("/orders/:id/cancel", handlers.AdminCancelOrder(db, paymentProvider))
+		admin.POST("/orders/:id/refunds", handlers.RefundOrder(db, paymentProvider))
+		admin.POST("/orders/:id/refunds/:refundId/retry", handlers.RetryOrderRefund(db, paymentProvider))
+		admin.PUT("/orders/:id/picking/:productId", handlers.PickOrderItem(db))
+		admin.POST("/orders/:id/picking/complete", handlers.CompleteOrderPicking(db, paymentProvider))
 		admin.DELETE("/orders/:id", handlers.DeleteOrder(db))
 	}
 	port := os.Getenv("PORT")
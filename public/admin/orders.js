@@ -1,7 +1,10 @@
 requireAuth();
 
-const ORDERS_API_URL = "/orders";
-const DELETE_ORDER_API_URL = "/admin/api/orders";
+const ORDERS_API_URL = "/admin/api/orders";
+const pageSize = 20;
+
+let currentPage = 1;
+let totalPages = 1;
 
 function formatDateTime(value) {
   const date = value ? new Date(value) : null;
@@ -119,6 +122,18 @@ function buildDetailRow(order, columnCount) {
   total.textContent = `Toplam: ${formatCurrency(order && order.totalPrice)}`;
   summaryList.appendChild(total);
 
+  if (order && typeof order.refundedAmount === "number" && order.refundedAmount > 0) {
+    const refunded = document.createElement("span");
+    refunded.textContent = `↩️ İade / düşülen: ${formatCurrency(order.refundedAmount)}`;
+    summaryList.appendChild(refunded);
+  }
+
+  if (order && typeof order.amountDue === "number") {
+    const due = document.createElement("strong");
+    due.textContent = `Tahsil edilecek: ${formatCurrency(order.amountDue)}`;
+    summaryList.appendChild(due);
+  }
+
   summaryCard.appendChild(summaryList);
 
   grid.appendChild(addressCard);
@@ -161,7 +176,9 @@ function buildDetailRow(order, columnCount) {
 
       const qtyCell = document.createElement("td");
       qtyCell.className = "numeric";
-      qtyCell.textContent = typeof item.quantity === "number" ? item.quantity : "-";
+      qtyCell.textContent = typeof item.quantity === "number"
+        ? item.quantity + (item.unit && item.unit !== "piece" ? " " + item.unit : "")
+        : "-";
       itemRow.appendChild(qtyCell);
 
       const priceCell = document.createElement("td");
@@ -220,17 +237,18 @@ function renderOrders(orders) {
     const row = document.createElement("tr");
     row.className = "order-row";
     const orderId = getId(order) || "-";
+    const orderLabel = order && order.number ? `#${order.number}` : orderId;
     const orderKey = normalizeOrderId(order, index);
     const customerTitle = order && order.customer && order.customer.title ? order.customer.title : "-";
     const itemCount = Array.isArray(order && order.items) ? order.items.length : 0;
 
     const cells = [
-      orderId,
+      orderLabel,
       formatDateTime(order && order.createdAt),
       customerTitle,
       order && order.paymentMethod ? order.paymentMethod : "-",
       itemCount,
-      formatCurrency(order && order.totalPrice),
+      formatCurrency(order && typeof order.amountDue === "number" ? order.amountDue : order && order.totalPrice),
     ];
 
     cells.forEach((value, index) => {
@@ -282,19 +300,85 @@ function renderOrders(orders) {
   });
 }
 
-async function loadOrders() {
+function buildOrdersUrl(page) {
+  const params = new URLSearchParams({
+    page: String(page),
+    limit: String(pageSize),
+  });
+
+  const form = document.getElementById("orderFilters");
+  if (form) {
+    new FormData(form).forEach((value, key) => {
+      const trimmed = String(value).trim();
+      if (trimmed) params.set(key, trimmed);
+    });
+  }
+
+  return `${ORDERS_API_URL}?${params.toString()}`;
+}
+
+function renderSummary(summary) {
+  const container = document.getElementById("ordersSummary");
+  if (!container) return;
+  if (!summary) {
+    container.textContent = "";
+    return;
+  }
+
+  const byStatus = summary.byStatus || {};
+  const parts = Object.keys(byStatus).map((status) => `${status}: ${byStatus[status]}`);
+  parts.push(`Ciro: ${formatCurrency(summary.totalRevenue)}`);
+  container.textContent = parts.join(" · ");
+}
+
+function renderPagination() {
+  const container = document.getElementById("ordersPagination");
+  if (!container) return;
+
+  container.innerHTML = "";
+
+  const label = document.createElement("span");
+  label.className = "pagination-label";
+  label.textContent = `Sayfa ${currentPage} / ${Math.max(totalPages, 1)}`;
+  container.appendChild(label);
+
+  const addButton = (text, page, disabled) => {
+    const button = document.createElement("button");
+    button.type = "button";
+    button.textContent = text;
+    button.disabled = disabled;
+    button.addEventListener("click", () => {
+      if (page === currentPage) return;
+      loadOrders(page);
+    });
+    container.appendChild(button);
+  };
+
+  addButton("Önceki", currentPage - 1, currentPage <= 1);
+  addButton("Sonraki", currentPage + 1, currentPage >= totalPages);
+}
+
+async function loadOrders(page) {
+  const targetPage = page || 1;
   setText("ordersStatus", "Siparişler yükleniyor...");
-  const res = await fetch(ORDERS_API_URL, { headers: authHeaders() });
+  const res = await fetch(buildOrdersUrl(targetPage), { headers: authHeaders() });
   if (handleUnauthorized(res)) return;
   const payload = await safeJson(res);
   if (!res.ok) {
     setText("ordersStatus", "Hata: siparişler getirilemedi");
+    clearTable();
     addEmptyRow("Siparişler yüklenemedi");
     return;
   }
 
-  const data = payload && payload.data ? payload.data : payload || [];
+  const data = payload && payload.data ? payload.data : [];
+  const pagination = payload && payload.pagination ? payload.pagination : {};
+  currentPage = Number.isFinite(pagination.page) ? pagination.page : targetPage;
+  totalPages = Number.isFinite(pagination.totalPages) ? pagination.totalPages : 1;
+
   renderOrders(data);
+  renderSummary(payload && payload.summary);
+  renderPagination();
   setText("ordersStatus", "");
 }
 
@@ -304,7 +388,7 @@ async function deleteOrder(orderId) {
   }
 
   setText("ordersStatus", "Sipariş siliniyor...");
-  const res = await fetch(`${DELETE_ORDER_API_URL}/${orderId}`, {
+  const res = await fetch(`${ORDERS_API_URL}/${orderId}`, {
     method: "DELETE",
     headers: authHeaders(),
   });
@@ -316,7 +400,16 @@ async function deleteOrder(orderId) {
   }
 
   setText("ordersStatus", "");
-  await loadOrders();
+  await loadOrders(currentPage);
 }
 
-document.addEventListener("DOMContentLoaded", loadOrders);
+document.addEventListener("DOMContentLoaded", () => {
+  const form = document.getElementById("orderFilters");
+  if (form) {
+    form.addEventListener("submit", (event) => {
+      event.preventDefault();
+      loadOrders(1);
+    });
+  }
+  loadOrders(1);
+});
@@ -393,7 +393,7 @@ function renderProductList(data) {
     const stockInput = document.createElement("input");
     stockInput.type = "number";
     stockInput.min = "0";
-    stockInput.step = "1";
+    stockInput.step = "any";
     stockInput.className = "table-input";
     stockInput.placeholder = "Stok";
     stockInput.value = stockValue === null ? "" : stockValue;
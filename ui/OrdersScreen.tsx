@@ -1,7 +1,14 @@
 import React from "react";
 import { FlatList, SafeAreaView, StatusBar, StyleSheet, Text, View } from "react-native";
 
-export type OrderStatus = "pending" | "preparing" | "delivered";
+export type OrderStatus =
+  | "pending"
+  | "confirmed"
+  | "preparing"
+  | "out_for_delivery"
+  | "delivered"
+  | "cancelled"
+  | "rejected";
 
 export interface OrderItem {
   id: string;
@@ -13,8 +20,12 @@ export interface OrderItem {
 
 const STATUS_STYLES: Record<OrderStatus, { label: string; backgroundColor: string; color: string }> = {
   pending: { label: "Pending", backgroundColor: "#FFF4E5", color: "#C26C02" },
+  confirmed: { label: "Confirmed", backgroundColor: "#F1EDFF", color: "#5B3CC4" },
   preparing: { label: "Preparing", backgroundColor: "#E8F3FF", color: "#1B6DD1" },
+  out_for_delivery: { label: "On the way", backgroundColor: "#E6F7F8", color: "#0E7C86" },
   delivered: { label: "Delivered", backgroundColor: "#E8FBF1", color: "#1F8A4D" },
+  cancelled: { label: "Cancelled", backgroundColor: "#F2F2F2", color: "#6B6B6B" },
+  rejected: { label: "Rejected", backgroundColor: "#FDECEC", color: "#C62828" },
 };
 
 interface OrderCardProps {